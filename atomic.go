@@ -0,0 +1,137 @@
+package avltree
+
+import (
+	"cmp"
+	"iter"
+	"sync"
+	"sync/atomic"
+)
+
+// AtomicTree is a tree for read-heavy concurrent use. Writers are
+// serialized and build a new persistent version of the tree, sharing the
+// unchanged nodes, which is then published atomically. Readers never
+// lock; each read sees one complete version.
+type AtomicTree[T any] struct {
+	mu      sync.Mutex                    // serializes writers
+	current atomic.Pointer[Persistent[T]] // the published version
+}
+
+// NewAtomicTree returns an initialized atomic tree.
+func NewAtomicTree[T any](c func(T, T) int, flags byte) *AtomicTree[T] {
+	a := &AtomicTree[T]{}
+	a.current.Store(NewPersistent(c, flags))
+	return a
+}
+
+// NewAtomicTreeOrdered returns an initialized atomic tree using
+// ordered types.
+func NewAtomicTreeOrdered[T cmp.Ordered](flags byte) *AtomicTree[T] {
+	return NewAtomicTree(cmp.Compare[T], flags)
+}
+
+// Load returns the current version of the tree. It remains valid and
+// unchanged however the tree is modified afterward, so a reader needing
+// several consistent lookups should use it rather than the methods of
+// the AtomicTree.
+func (a *AtomicTree[T]) Load() *Persistent[T] {
+	return a.current.Load()
+}
+
+// publish replaces the current version with the result of f, which is
+// called with the current version while writers are locked out.
+func (a *AtomicTree[T]) publish(f func(p *Persistent[T]) *Persistent[T]) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.current.Store(f(a.current.Load()))
+}
+
+// Add adds an item to the tree, returning false if it was not added
+// because it is a duplicate.
+func (a *AtomicTree[T]) Add(o T) bool {
+	added := false
+	a.publish(func(p *Persistent[T]) *Persistent[T] {
+		n := p.Add(o)
+		added = n != p
+		return n
+	})
+	return added
+}
+
+// ReplaceOrInsert adds an item to the tree, replacing an existing item
+// that compares equal to it.
+func (a *AtomicTree[T]) ReplaceOrInsert(o T) {
+	a.publish(func(p *Persistent[T]) *Persistent[T] {
+		return p.ReplaceOrInsert(o)
+	})
+}
+
+// Remove removes the element matching the given value, returning true
+// if it was found.
+func (a *AtomicTree[T]) Remove(o T) bool {
+	removed := false
+	a.publish(func(p *Persistent[T]) *Persistent[T] {
+		n := p.Remove(o)
+		removed = n != p
+		return n
+	})
+	return removed
+}
+
+// Batch calls f with a tree holding the current elements, and publishes
+// its contents as a single new version once f returns. Readers see either
+// none or all of the changes made by f. The tree shares its unchanged
+// nodes with earlier versions and must not be used after f returns.
+//
+// Pointers returned by methods of the tree such as Find, At, Min and Max
+// refer to nodes that concurrent readers may be using, so they must never
+// be written through; to change an element, use ReplaceOrInsert instead.
+func (a *AtomicTree[T]) Batch(f func(t *Tree[T])) {
+	a.publish(func(p *Persistent[T]) *Persistent[T] {
+		n := p.edit()
+		t := n.t
+		f(&t)
+		n.t = t
+		t.owner = new(owner) // stray changes to t must not touch n
+		return n
+	})
+}
+
+// Len returns the number of elements in the current version.
+func (a *AtomicTree[T]) Len() int {
+	return a.Load().Len()
+}
+
+// Get returns a copy of the element matching the given key value in the
+// current version, and true if it was found.
+func (a *AtomicTree[T]) Get(key T) (T, bool) {
+	return a.Load().Get(key)
+}
+
+// Contains returns true if an element matching the given key value is
+// in the current version.
+func (a *AtomicTree[T]) Contains(key T) bool {
+	return a.Load().Contains(key)
+}
+
+// At returns a copy of the element at the given index in the current
+// version, and true if the index is valid.
+func (a *AtomicTree[T]) At(index int) (T, bool) {
+	return a.Load().At(index)
+}
+
+// All returns an iterator over all the elements of the version current
+// when iteration starts, in order.
+func (a *AtomicTree[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		a.Load().Do(yield)
+	}
+}
+
+// Range returns an iterator over the elements between lo and hi of the
+// version current when iteration starts, in order. The bounds determine
+// whether lo and hi themselves are included.
+func (a *AtomicTree[T]) Range(lo, hi T, bounds Bounds) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		a.Load().t.DoBetween(lo, hi, bounds, yield)
+	}
+}
@@ -0,0 +1,93 @@
+package avltree
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestAtomicTree(t *testing.T) {
+	a := NewAtomicTreeOrdered[int](0)
+
+	if !a.Add(1) || a.Add(1) || a.Len() != 1 {
+		t.Errorf("Add should add 1 once: %d\n", a.Len())
+	}
+
+	v := a.Load()
+
+	if !a.Remove(1) || a.Remove(1) || a.Len() != 0 {
+		t.Errorf("Remove should remove 1 once: %d\n", a.Len())
+	}
+
+	if v.Len() != 1 || !v.Contains(1) {
+		t.Errorf("Loaded version should not change: %d\n", v.Len())
+	}
+
+	a.Batch(func(t *Tree[int]) {
+		for j := 0; j < 100; j++ {
+			t.Add(j)
+		}
+		t.RemoveRange(10, 20, HalfOpen)
+	})
+
+	if a.Len() != 90 || a.Contains(15) || !a.Contains(20) {
+		t.Errorf("Batch should publish 90 elements: %d\n", a.Len())
+	}
+
+	if x, ok := a.At(10); !ok || x != 20 {
+		t.Errorf("At(10) should be 20: %v/%v\n", x, ok)
+	}
+
+	n := 0
+	for range a.Range(0, 30, HalfOpen) {
+		n++
+	}
+
+	if n != 20 {
+		t.Errorf("Range(0, 30) should have 20 elements: %d\n", n)
+	}
+}
+
+func TestAtomicTreeReaders(t *testing.T) {
+	a := NewAtomicTreeOrdered[int](0)
+	var done atomic.Bool
+	var wg sync.WaitGroup
+
+	// readers check that every version they see holds whole batches
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for !done.Load() {
+				v := a.Load()
+				if v.Len()%10 != 0 {
+					t.Errorf("Reader saw a partial batch: %d\n", v.Len())
+					return
+				}
+				prev := -1
+				for x := range v.All() {
+					if x <= prev {
+						t.Errorf("Reader saw elements out of order: %d after %d\n", x, prev)
+						return
+					}
+					prev = x
+				}
+			}
+		}()
+	}
+
+	for j := 0; j < 200; j++ {
+		a.Batch(func(t *Tree[int]) {
+			for k := 0; k < 10; k++ {
+				t.Add(j*10 + k)
+			}
+		})
+	}
+	done.Store(true)
+	wg.Wait()
+
+	if a.Len() != 2000 {
+		t.Errorf("Tree should have 2000 elements: %d\n", a.Len())
+	}
+	checkTree(t, &a.Load().t)
+}
@@ -0,0 +1,252 @@
+package avltree
+
+// Cursor is a position in a tree that can be moved forward and backward
+// one element at a time. A cursor is obtained from SeekFirst, SeekLast,
+// Seek or SeekIndex. It may also be positioned before the first element
+// or after the last one, in which case it is not valid.
+//
+// Changing the tree other than through the cursor's Delete method
+// invalidates the cursor; it should be obtained again by seeking. Using an
+// invalidated cursor panics with ErrConcurrentModification.
+type Cursor[T any] struct {
+	t     *Tree[T]
+	path  []*treeNode[T] // nodes from the root to the current element
+	index int            // index of the current element
+	mods  int            // modification count of the tree when positioned
+}
+
+// seek positions the cursor at the given index. An index outside the
+// tree leaves the cursor before the first element or after the last one.
+func (c *Cursor[T]) seek(index int) {
+	c.path = c.path[:0]
+	c.mods = c.t.mods
+	n := c.t.Len()
+	if index < 0 {
+		c.index = -1
+		return
+	}
+	if index >= n {
+		c.index = n
+		return
+	}
+
+	c.index = index
+	node := c.t.root
+	for {
+		c.path = append(c.path, node)
+		ls := node.leftSize()
+		if index < ls {
+			node = node.left
+		} else if index == ls {
+			return
+		} else {
+			index -= ls + 1
+			node = node.right
+		}
+	}
+}
+
+// check panics if the tree was modified since the cursor was positioned.
+func (c *Cursor[T]) check() {
+	if c.mods != c.t.mods {
+		panic(ErrConcurrentModification)
+	}
+}
+
+func (t *Tree[T]) cursor(index int) *Cursor[T] {
+	c := &Cursor[T]{t: t}
+	c.seek(index)
+	return c
+}
+
+// SeekFirst returns a cursor positioned at the first element of the tree.
+func (t *Tree[T]) SeekFirst() *Cursor[T] {
+	return t.cursor(0)
+}
+
+// SeekLast returns a cursor positioned at the last element of the tree.
+func (t *Tree[T]) SeekLast() *Cursor[T] {
+	return t.cursor(t.Len() - 1)
+}
+
+// Seek returns a cursor positioned at the first element greater than or
+// equal to key. If there is no such element, the cursor is positioned
+// after the last element.
+func (t *Tree[T]) Seek(key T) *Cursor[T] {
+	return t.cursor(t.Rank(key))
+}
+
+// SeekIndex returns a cursor positioned at the element with the given
+// index.
+func (t *Tree[T]) SeekIndex(index int) *Cursor[T] {
+	return t.cursor(index)
+}
+
+// Valid returns true if the cursor is positioned at an element.
+func (c *Cursor[T]) Valid() bool {
+	return len(c.path) > 0
+}
+
+// Index returns the index of the current element. It is -1 if the cursor
+// is before the first element, and the length of the tree if it is after
+// the last one.
+func (c *Cursor[T]) Index() int {
+	return c.index
+}
+
+// Value returns the current element, or the zero value if the cursor is
+// not valid.
+func (c *Cursor[T]) Value() T {
+	c.check()
+	if len(c.path) == 0 {
+		var zero T
+		return zero
+	}
+	return c.path[len(c.path)-1].value
+}
+
+// Next moves the cursor to the next element, returning false if there is
+// none. A cursor before the first element moves to the first element.
+func (c *Cursor[T]) Next() bool {
+	c.check()
+	if len(c.path) == 0 {
+		if c.index < 0 {
+			c.seek(0)
+		}
+		return len(c.path) > 0
+	}
+
+	c.index++
+	node := c.path[len(c.path)-1]
+	if node.right != nil {
+		for node = node.right; node != nil; node = node.left {
+			c.path = append(c.path, node)
+		}
+		return true
+	}
+	for len(c.path) > 1 {
+		child := c.path[len(c.path)-1]
+		c.path = c.path[:len(c.path)-1]
+		if c.path[len(c.path)-1].left == child {
+			return true
+		}
+	}
+	c.path = c.path[:0] // past the last element
+	return false
+}
+
+// Prev moves the cursor to the previous element, returning false if there
+// is none. A cursor after the last element moves to the last element.
+func (c *Cursor[T]) Prev() bool {
+	c.check()
+	if len(c.path) == 0 {
+		if c.index >= c.t.Len() {
+			c.seek(c.t.Len() - 1)
+		}
+		return len(c.path) > 0
+	}
+
+	c.index--
+	node := c.path[len(c.path)-1]
+	if node.left != nil {
+		for node = node.left; node != nil; node = node.right {
+			c.path = append(c.path, node)
+		}
+		return true
+	}
+	for len(c.path) > 1 {
+		child := c.path[len(c.path)-1]
+		c.path = c.path[:len(c.path)-1]
+		if c.path[len(c.path)-1].right == child {
+			return true
+		}
+	}
+	c.path = c.path[:0] // before the first element
+	return false
+}
+
+// Delete removes the current element from the tree and moves the cursor
+// to the element that followed it, returning false if the cursor is not
+// valid. The cursor remains usable, so Prev moves to the element that
+// preceded the removed one.
+func (c *Cursor[T]) Delete() bool {
+	c.check()
+	if len(c.path) == 0 {
+		return false
+	}
+	c.t.RemoveAt(c.index)
+	c.seek(c.index)
+	return true
+}
+
+// MapCursor is a position in a map that can be moved forward and
+// backward one element at a time. It behaves like a Cursor.
+type MapCursor[K, V any] struct {
+	c *Cursor[Pair[K, V]]
+}
+
+// SeekFirst returns a cursor positioned at the first element of the map.
+func (m *Map[K, V]) SeekFirst() *MapCursor[K, V] {
+	return &MapCursor[K, V]{m.t.SeekFirst()}
+}
+
+// SeekLast returns a cursor positioned at the last element of the map.
+func (m *Map[K, V]) SeekLast() *MapCursor[K, V] {
+	return &MapCursor[K, V]{m.t.SeekLast()}
+}
+
+// Seek returns a cursor positioned at the first element with a key
+// greater than or equal to key. If there is no such element, the cursor
+// is positioned after the last element.
+func (m *Map[K, V]) Seek(key K) *MapCursor[K, V] {
+	return &MapCursor[K, V]{m.t.Seek(Pair[K, V]{Key: key})}
+}
+
+// SeekIndex returns a cursor positioned at the element with the given
+// index.
+func (m *Map[K, V]) SeekIndex(index int) *MapCursor[K, V] {
+	return &MapCursor[K, V]{m.t.SeekIndex(index)}
+}
+
+// Valid returns true if the cursor is positioned at an element.
+func (c *MapCursor[K, V]) Valid() bool {
+	return c.c.Valid()
+}
+
+// Index returns the index of the current element. It is -1 if the cursor
+// is before the first element, and the length of the map if it is after
+// the last one.
+func (c *MapCursor[K, V]) Index() int {
+	return c.c.Index()
+}
+
+// Key returns the key of the current element, or the zero value if the
+// cursor is not valid.
+func (c *MapCursor[K, V]) Key() K {
+	return c.c.Value().Key
+}
+
+// Value returns the value of the current element, or the zero value if
+// the cursor is not valid.
+func (c *MapCursor[K, V]) Value() V {
+	return c.c.Value().Value
+}
+
+// Next moves the cursor to the next element, returning false if there is
+// none.
+func (c *MapCursor[K, V]) Next() bool {
+	return c.c.Next()
+}
+
+// Prev moves the cursor to the previous element, returning false if there
+// is none.
+func (c *MapCursor[K, V]) Prev() bool {
+	return c.c.Prev()
+}
+
+// Delete removes the current element from the map and moves the cursor
+// to the element that followed it, returning false if the cursor is not
+// valid.
+func (c *MapCursor[K, V]) Delete() bool {
+	return c.c.Delete()
+}
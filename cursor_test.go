@@ -0,0 +1,273 @@
+package avltree
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCursor(t *testing.T) {
+	tree := NewOrdered[int](0)
+	for i := 0; i < 1000; i++ {
+		tree.Add(i)
+	}
+
+	i := 0
+	for c := tree.SeekFirst(); c.Valid(); c.Next() {
+		if c.Value() != i || c.Index() != i {
+			t.Errorf("Expected %d at %d: %d\n", i, c.Index(), c.Value())
+		}
+		i++
+	}
+	if i != 1000 {
+		t.Errorf("Expected 1000 elements: %d\n", i)
+	}
+
+	i = 999
+	for c := tree.SeekLast(); c.Valid(); c.Prev() {
+		if c.Value() != i || c.Index() != i {
+			t.Errorf("Expected %d at %d: %d\n", i, c.Index(), c.Value())
+		}
+		i--
+	}
+	if i != -1 {
+		t.Errorf("Expected 1000 elements in reverse: %d\n", 999-i)
+	}
+
+	c := tree.SeekIndex(500)
+	for j := 0; j < 10; j++ {
+		c.Next()
+	}
+	for j := 0; j < 5; j++ {
+		c.Prev()
+	}
+	if c.Value() != 505 || c.Index() != 505 {
+		t.Errorf("Expected 505: %d at %d\n", c.Value(), c.Index())
+	}
+
+	c = tree.SeekLast()
+	if c.Next() || c.Valid() || c.Index() != 1000 {
+		t.Errorf("Cursor should be after the last element: %d\n", c.Index())
+	}
+	if !c.Prev() || c.Value() != 999 {
+		t.Errorf("Prev should return to the last element: %d\n", c.Value())
+	}
+
+	c = tree.SeekFirst()
+	if c.Prev() || c.Valid() || c.Index() != -1 {
+		t.Errorf("Cursor should be before the first element: %d\n", c.Index())
+	}
+	if !c.Next() || c.Value() != 0 {
+		t.Errorf("Next should return to the first element: %d\n", c.Value())
+	}
+
+	if c := tree.SeekIndex(-5); c.Valid() || c.Index() != -1 {
+		t.Errorf("SeekIndex(-5) should be before the first element\n")
+	}
+	if c := tree.SeekIndex(2000); c.Valid() || c.Index() != 1000 {
+		t.Errorf("SeekIndex(2000) should be after the last element\n")
+	}
+
+	empty := NewOrdered[int](0)
+	if c := empty.SeekFirst(); c.Valid() || c.Next() || c.Prev() {
+		t.Errorf("Cursor on an empty tree should not be valid\n")
+	}
+}
+
+func TestCursorSeek(t *testing.T) {
+	tree := NewOrdered[int](AllowDuplicates)
+	for _, v := range []int{10, 20, 20, 20, 30} {
+		tree.Add(v)
+	}
+
+	c := tree.Seek(20)
+	if c.Value() != 20 || c.Index() != 1 {
+		t.Errorf("Seek(20) should find the first 20: %d at %d\n", c.Value(), c.Index())
+	}
+	c = tree.Seek(25)
+	if c.Value() != 30 || c.Index() != 4 {
+		t.Errorf("Seek(25) should find 30: %d at %d\n", c.Value(), c.Index())
+	}
+	c = tree.Seek(35)
+	if c.Valid() || c.Index() != 5 {
+		t.Errorf("Seek(35) should be after the last element: %d\n", c.Index())
+	}
+}
+
+func TestCursorDelete(t *testing.T) {
+	tree := NewOrdered[int](0)
+	for i := 0; i < 1000; i++ {
+		tree.Add(i)
+	}
+
+	c := tree.SeekFirst()
+	for c.Valid() {
+		if c.Value()%2 == 0 {
+			c.Delete()
+		} else {
+			c.Next()
+		}
+	}
+	if c.Delete() {
+		t.Errorf("Delete should fail on an invalid cursor\n")
+	}
+
+	if tree.Len() != 500 {
+		t.Errorf("Expected 500 elements: %d\n", tree.Len())
+	}
+	for v := range tree.All() {
+		if v%2 == 0 {
+			t.Errorf("Even element left in the tree: %d\n", v)
+		}
+	}
+	checkTree(t, tree)
+
+	c = tree.Seek(501)
+	c.Delete()
+	if c.Value() != 503 || !c.Prev() || c.Value() != 499 {
+		t.Errorf("Cursor should stay usable after Delete: %d\n", c.Value())
+	}
+
+	// deleting through a cursor leaves a clone alone
+	clone := tree.Clone()
+	for c := tree.SeekFirst(); c.Valid(); {
+		c.Delete()
+	}
+	if tree.Len() != 0 || clone.Len() != 499 {
+		t.Errorf("Expected 0 and 499 elements: %d, %d\n", tree.Len(), clone.Len())
+	}
+	checkTree(t, clone)
+}
+
+func TestCursorMergeJoin(t *testing.T) {
+	a := NewOrdered[int](0)
+	b := NewOrdered[int](0)
+	for i := 0; i < 100; i++ {
+		a.Add(i * 2)
+		b.Add(i * 3)
+	}
+
+	var both []int
+	ca, cb := a.SeekFirst(), b.SeekFirst()
+	for ca.Valid() && cb.Valid() {
+		switch {
+		case ca.Value() < cb.Value():
+			ca.Next()
+		case ca.Value() > cb.Value():
+			cb.Next()
+		default:
+			both = append(both, ca.Value())
+			ca.Next()
+			cb.Next()
+		}
+	}
+
+	var want []int
+	for i := 0; i < 200; i += 6 {
+		want = append(want, i)
+	}
+	if !slices.Equal(both, want) {
+		t.Errorf("Merge join should find multiples of 6: %v\n", both)
+	}
+}
+
+func TestMapCursor(t *testing.T) {
+	m := NewMapOrdered[string, int]()
+	for i, k := range []string{"a", "b", "c", "d", "e"} {
+		m.Add(k, i)
+	}
+
+	var keys []string
+	for c := m.SeekFirst(); c.Valid(); c.Next() {
+		keys = append(keys, c.Key())
+		if c.Value() != c.Index() {
+			t.Errorf("Expected value %d for %s: %d\n", c.Index(), c.Key(), c.Value())
+		}
+	}
+	if !slices.Equal(keys, []string{"a", "b", "c", "d", "e"}) {
+		t.Errorf("Unexpected keys: %v\n", keys)
+	}
+
+	c := m.Seek("bb")
+	if c.Key() != "c" {
+		t.Errorf("Seek(bb) should find c: %s\n", c.Key())
+	}
+	c.Delete()
+	if c.Key() != "d" || !c.Prev() || c.Key() != "b" || m.Contains("c") {
+		t.Errorf("Delete should remove c: %s\n", c.Key())
+	}
+
+	c = m.SeekLast()
+	if c.Key() != "e" || c.Next() || c.Key() != "" {
+		t.Errorf("Cursor should move past e: %s\n", c.Key())
+	}
+	if c := m.SeekIndex(1); c.Key() != "b" {
+		t.Errorf("SeekIndex(1) should find b: %s\n", c.Key())
+	}
+}
+
+func TestCursorConcurrentModification(t *testing.T) {
+	tree := NewOrdered[int](0)
+	for i := 0; i < 100; i++ {
+		tree.Add(i)
+	}
+
+	c := tree.Seek(50)
+	c.Delete() // through the cursor
+	if !c.Next() || c.Value() != 52 {
+		t.Errorf("Cursor should be usable after its own Delete: %d\n", c.Value())
+	}
+
+	d := tree.Seek(10)
+	tree.Remove(20)
+	for name, f := range map[string]func(){
+		"Next":   func() { d.Next() },
+		"Prev":   func() { d.Prev() },
+		"Value":  func() { d.Value() },
+		"Delete": func() { d.Delete() },
+	} {
+		if r := panics(f); r != ErrConcurrentModification {
+			t.Errorf("%s after Remove should panic: %v\n", name, r)
+		}
+	}
+
+	if r := panics(func() { c.Next() }); r != ErrConcurrentModification {
+		t.Errorf("Cursor should be invalidated by another cursor: %v\n", r)
+	}
+
+	e := tree.Seek(30)
+	if !e.Next() || e.Value() != 31 {
+		t.Errorf("Seeking again should give a usable cursor: %d\n", e.Value())
+	}
+}
+
+func TestCursorReplace(t *testing.T) {
+	m := NewMapOrdered[int, string]()
+	for i := 0; i < 10; i++ {
+		m.Add(i, "old")
+	}
+
+	// replacing in place is seen by the cursor
+	c := m.Seek(5)
+	m.Set(5, "new")
+	if c.Value() != "new" {
+		t.Errorf("Cursor should see a value replaced in place: %s\n", c.Value())
+	}
+
+	// replacing a shared node copies it, so the cursor is invalidated
+	clone := m.Clone()
+	c = m.Seek(5)
+	m.Set(5, "newer")
+	if r := panics(func() { c.Value() }); r != ErrConcurrentModification {
+		t.Errorf("Cursor should be invalidated by a copying Set: %v\n", r)
+	}
+	if v, _ := clone.Get(5); v != "new" {
+		t.Errorf("Clone should keep the old value: %s\n", v)
+	}
+
+	// the copied nodes now belong to m, so updates are in place again
+	c = m.Seek(5)
+	m.Update(5, func(old string, exists bool) string { return "updated" })
+	if c.Value() != "updated" {
+		t.Errorf("Cursor should see a value updated in place: %s\n", c.Value())
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"cmp"
 	"context"
 	"io"
+	"iter"
 )
 
 type Pair[K, V any] struct {
@@ -43,6 +44,17 @@ func (m *Map[K, V]) Clear() {
 	m.t.Clear()
 }
 
+// Clone returns a copy of the map in constant time. The two maps share
+// their nodes, and each copies the nodes it changes on its next
+// modification. Like other reads, Clone may be called by several threads
+// at once, provided that no threads are modifying the map. Pointers
+// returned by methods such as Find, At, Min and Max refer to nodes that
+// may be shared, so once a map is cloned they should not be written
+// through.
+func (m *Map[K, V]) Clone() *Map[K, V] {
+	return &Map[K, V]{t: *m.t.Clone()}
+}
+
 // Height returns the "height" of the map, meaning the
 // number of levels.
 func (m *Map[K, V]) Height() int {
@@ -67,6 +79,16 @@ func (m *Map[K, V]) At(index int) *Pair[K, V] {
 	return m.t.At(index)
 }
 
+// Min returns the element with the smallest key, or nil if the map is empty.
+func (m *Map[K, V]) Min() *Pair[K, V] {
+	return m.t.Min()
+}
+
+// Max returns the element with the largest key, or nil if the map is empty.
+func (m *Map[K, V]) Max() *Pair[K, V] {
+	return m.t.Max()
+}
+
 // Find returns the element where the comparison function matches
 // the node's value and the given key value.
 func (m *Map[K, V]) Find(key K) *V {
@@ -77,31 +99,212 @@ func (m *Map[K, V]) Find(key K) *V {
 	return nil
 }
 
+// Get returns a copy of the value stored for key, and true if it was
+// found. Unlike Find, no pointer into the map is returned.
+func (m *Map[K, V]) Get(key K) (V, bool) {
+	if v := m.Find(key); v != nil {
+		return *v, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Contains returns true if the key is in the map.
+func (m *Map[K, V]) Contains(key K) bool {
+	return m.t.Find(Pair[K, V]{Key: key}) != nil
+}
+
+// Floor returns the element with the greatest key less than or equal to
+// key, along with its index. If there is no such element, nil and -1
+// are returned.
+func (m *Map[K, V]) Floor(key K) (*Pair[K, V], int) {
+	return m.t.Floor(Pair[K, V]{Key: key})
+}
+
+// Ceiling returns the element with the smallest key greater than or equal
+// to key, along with its index. If there is no such element, nil and -1
+// are returned.
+func (m *Map[K, V]) Ceiling(key K) (*Pair[K, V], int) {
+	return m.t.Ceiling(Pair[K, V]{Key: key})
+}
+
+// Lower returns the element with the greatest key strictly less than key,
+// along with its index. If there is no such element, nil and -1 are returned.
+func (m *Map[K, V]) Lower(key K) (*Pair[K, V], int) {
+	return m.t.Lower(Pair[K, V]{Key: key})
+}
+
+// Higher returns the element with the smallest key strictly greater than
+// key, along with its index. If there is no such element, nil and -1
+// are returned.
+func (m *Map[K, V]) Higher(key K) (*Pair[K, V], int) {
+	return m.t.Higher(Pair[K, V]{Key: key})
+}
+
+// Rank returns the number of elements with keys strictly less than key.
+// The key does not need to be present in the map.
+func (m *Map[K, V]) Rank(key K) int {
+	return m.t.Rank(Pair[K, V]{Key: key})
+}
+
+// IndexOf returns the index of the element matching key. If there is no
+// matching element, -1 and false are returned.
+func (m *Map[K, V]) IndexOf(key K) (int, bool) {
+	return m.t.IndexOf(Pair[K, V]{Key: key})
+}
+
+// CountRange returns the number of elements with keys between lo and hi
+// without visiting them. The bounds determine whether lo and hi themselves
+// are included.
+func (m *Map[K, V]) CountRange(lo, hi K, bounds Bounds) int {
+	return m.t.CountRange(Pair[K, V]{Key: lo}, Pair[K, V]{Key: hi}, bounds)
+}
+
 // Do calls function f for each element of the map, in order.
-// The function should not change the structure of the map underfoot.
+// The function should not change the structure of the map underfoot;
+// if it does and returns true, Do panics with ErrConcurrentModification.
 func (m *Map[K, V]) Do(f func(K, V) bool) {
 
-	if f != nil && m.t.root != nil {
-		iterateFunc[Pair[K, V]](func(e Pair[K, V]) bool {
+	if f != nil {
+		m.t.Do(func(e Pair[K, V]) bool {
+			return f(e.Key, e.Value)
+		})
+	}
+}
+
+// DoReverse calls function f for each element of the map, in reverse order.
+// The function should not change the structure of the map underfoot;
+// if it does and returns true, DoReverse panics with ErrConcurrentModification.
+func (m *Map[K, V]) DoReverse(f func(K, V) bool) {
+
+	if f != nil {
+		m.t.DoReverse(func(e Pair[K, V]) bool {
+			return f(e.Key, e.Value)
+		})
+	}
+}
+
+// DoBetween calls function f for each element of the map with a key
+// between lo and hi, in order. The bounds determine whether lo and hi
+// themselves are included. The function should not change the structure
+// of the map underfoot.
+func (m *Map[K, V]) DoBetween(lo, hi K, bounds Bounds, f func(K, V) bool) {
+	if f != nil {
+		m.t.DoBetween(Pair[K, V]{Key: lo}, Pair[K, V]{Key: hi}, bounds, func(e Pair[K, V]) bool {
+			return f(e.Key, e.Value)
+		})
+	}
+}
+
+// DoFrom calls function f for each element of the map with a key greater
+// than or equal to key, in order. The function should not change the
+// structure of the map underfoot.
+func (m *Map[K, V]) DoFrom(key K, f func(K, V) bool) {
+	if f != nil {
+		m.t.DoFrom(Pair[K, V]{Key: key}, func(e Pair[K, V]) bool {
+			return f(e.Key, e.Value)
+		})
+	}
+}
+
+// DoRange calls function f for each element of the map with an index
+// in [start, end), in order. Indexes outside the map are ignored. The
+// function should not change the structure of the map underfoot.
+func (m *Map[K, V]) DoRange(start, end int, f func(K, V) bool) {
+	if f != nil {
+		m.t.DoRange(start, end, func(e Pair[K, V]) bool {
 			return f(e.Key, e.Value)
-		}).iterate(m.t.root)
+		})
 	}
 }
 
 // Iter returns a channel you can read through to fetch all the items.
+// The items are those in the map when Iter is called, and the map may
+// be changed while the channel is read.
 func (m *Map[K, V]) Iter() <-chan Pair[K, V] {
 	c := make(chan (Pair[K, V]))
-	go m.t.chanIterate(context.Background(), c)
+	s := m.t.Clone()
+	go s.chanIterate(context.Background(), c, s.Do)
 	return c
 }
 
 // IterContext returns a channel you can read through to fetch all the items.
 func (m *Map[K, V]) IterContext(ctx context.Context) <-chan Pair[K, V] {
 	c := make(chan (Pair[K, V]))
-	go m.t.chanIterate(ctx, c)
+	s := m.t.Clone()
+	go s.chanIterate(ctx, c, s.Do)
+	return c
+}
+
+// IterReverse returns a channel you can read through to fetch all the items
+// in reverse order.
+func (m *Map[K, V]) IterReverse() <-chan Pair[K, V] {
+	c := make(chan (Pair[K, V]))
+	s := m.t.Clone()
+	go s.chanIterate(context.Background(), c, s.DoReverse)
+	return c
+}
+
+// IterReverseContext returns a channel you can read through to fetch all
+// the items in reverse order.
+func (m *Map[K, V]) IterReverseContext(ctx context.Context) <-chan Pair[K, V] {
+	c := make(chan (Pair[K, V]))
+	s := m.t.Clone()
+	go s.chanIterate(ctx, c, s.DoReverse)
 	return c
 }
 
+// All returns an iterator over the keys and values of the map, in order.
+// No goroutine is used and the loop may be exited early.
+func (m *Map[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m.Do(yield)
+	}
+}
+
+// Backward returns an iterator over the keys and values of the map,
+// in reverse order.
+func (m *Map[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m.DoReverse(yield)
+	}
+}
+
+// Range returns an iterator over the keys and values of the map with keys
+// between lo and hi, in order. The bounds determine whether lo and hi
+// themselves are included.
+func (m *Map[K, V]) Range(lo, hi K, bounds Bounds) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m.DoBetween(lo, hi, bounds, yield)
+	}
+}
+
+// From returns an iterator over the keys and values of the map with keys
+// greater than or equal to key, in order.
+func (m *Map[K, V]) From(key K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m.DoFrom(key, yield)
+	}
+}
+
+// AllKeys returns an iterator over the keys of the map, in order.
+func (m *Map[K, V]) AllKeys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		m.t.Do(func(e Pair[K, V]) bool {
+			return yield(e.Key)
+		})
+	}
+}
+
+// AllValues returns an iterator over the values of the map, in key order.
+func (m *Map[K, V]) AllValues() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		m.t.Do(func(e Pair[K, V]) bool {
+			return yield(e.Value)
+		})
+	}
+}
+
 // Keys returns all the keys as a slice.
 func (m *Map[K, V]) Keys() []K {
 	arr := make([]K, m.t.Len())
@@ -128,6 +331,36 @@ func (m *Map[K, V]) Values() []V {
 	return arr
 }
 
+// Pairs returns the elements with an index in [start, end) as a slice.
+// Indexes outside the map are ignored.
+func (m *Map[K, V]) Pairs(start, end int) []Pair[K, V] {
+	return m.t.Slice(start, end)
+}
+
+// SliceKeys returns the keys of the elements with an index in [start, end)
+// as a slice. Indexes outside the map are ignored.
+func (m *Map[K, V]) SliceKeys(start, end int) []K {
+	start, end = m.t.clampRange(start, end)
+	arr := make([]K, 0, end-start)
+	m.t.DoRange(start, end, func(v Pair[K, V]) bool {
+		arr = append(arr, v.Key)
+		return true
+	})
+	return arr
+}
+
+// SliceValues returns the values of the elements with an index in
+// [start, end) as a slice. Indexes outside the map are ignored.
+func (m *Map[K, V]) SliceValues(start, end int) []V {
+	start, end = m.t.clampRange(start, end)
+	arr := make([]V, 0, end-start)
+	m.t.DoRange(start, end, func(v Pair[K, V]) bool {
+		arr = append(arr, v.Value)
+		return true
+	})
+	return arr
+}
+
 // Add adds an item to the map, returning a pair indicating the added
 // (or duplicate) item, and a flag indicating whether the item is the
 // duplicate that was found.
@@ -139,6 +372,30 @@ func (m *Map[K, V]) Add(k K, v V) (*V, bool) {
 	return nil, dupe
 }
 
+// Set adds an item to the map, replacing the value of an existing item
+// with the same key. If a value was replaced, it is returned along with true.
+func (m *Map[K, V]) Set(k K, v V) (old V, replaced bool) {
+	m.t.upsert(Pair[K, V]{Key: k}, func(p Pair[K, V], exists bool) Pair[K, V] {
+		if exists {
+			old, replaced = p.Value, true
+		}
+		p.Value = v
+		return p
+	})
+	return
+}
+
+// Update sets the value stored for key k to the result of f, which is
+// called with the current value and true if the key is present, or the
+// zero value and false if it is not. The new value is returned.
+func (m *Map[K, V]) Update(k K, f func(old V, exists bool) V) V {
+	p := m.t.upsert(Pair[K, V]{Key: k}, func(p Pair[K, V], exists bool) Pair[K, V] {
+		p.Value = f(p.Value, exists)
+		return p
+	})
+	return p.Value
+}
+
 // Remove removes the element matching the given value.
 func (m *Map[K, V]) Remove(key K) *V {
 	p := m.t.Remove(Pair[K, V]{Key: key})
@@ -153,6 +410,126 @@ func (m *Map[K, V]) RemoveAt(index int) *Pair[K, V] {
 	return m.t.RemoveAt(index)
 }
 
+// RemoveIndexRange removes the elements with an index in [start, end),
+// returning how many were removed. Indexes outside the map are ignored.
+func (m *Map[K, V]) RemoveIndexRange(start, end int) int {
+	return m.t.RemoveIndexRange(start, end)
+}
+
+// RemoveRange removes the elements with keys between lo and hi, returning
+// how many were removed. The bounds determine whether lo and hi themselves
+// are included.
+func (m *Map[K, V]) RemoveRange(lo, hi K, bounds Bounds) int {
+	return m.t.RemoveRange(Pair[K, V]{Key: lo}, Pair[K, V]{Key: hi}, bounds)
+}
+
+// RemoveIf removes all the elements for which f returns true, returning
+// how many were removed. The map is rebuilt in O(n) time.
+func (m *Map[K, V]) RemoveIf(f func(K, V) bool) int {
+	return m.t.RemoveIf(func(p Pair[K, V]) bool {
+		return f(p.Key, p.Value)
+	})
+}
+
+// Retain removes all the elements for which f returns false, returning
+// how many were removed. The map is rebuilt in O(n) time.
+func (m *Map[K, V]) Retain(f func(K, V) bool) int {
+	return m.t.RemoveIf(func(p Pair[K, V]) bool {
+		return !f(p.Key, p.Value)
+	})
+}
+
+// PopMin removes and returns the element with the smallest key,
+// or nil if the map is empty.
+func (m *Map[K, V]) PopMin() *Pair[K, V] {
+	return m.t.PopMin()
+}
+
+// PopMax removes and returns the element with the largest key,
+// or nil if the map is empty.
+func (m *Map[K, V]) PopMax() *Pair[K, V] {
+	return m.t.PopMax()
+}
+
+// Split removes the elements with keys greater than or equal to key from
+// the map and returns them as a new map. This takes O(log n) time.
+func (m *Map[K, V]) Split(key K) *Map[K, V] {
+	return &Map[K, V]{t: *m.t.Split(Pair[K, V]{Key: key})}
+}
+
+// SplitAt removes the elements from the given index onward from the map
+// and returns them as a new map. This takes O(log n) time.
+func (m *Map[K, V]) SplitAt(index int) *Map[K, V] {
+	return &Map[K, V]{t: *m.t.SplitAt(index)}
+}
+
+// Join moves all the elements of other to the end of the map in O(log n)
+// time, leaving other empty. Every key of other must sort after every key
+// of the map; if they do not, nothing is moved and false is returned.
+func (m *Map[K, V]) Join(other *Map[K, V]) bool {
+	return m.t.Join(&other.t)
+}
+
+// resolver adapts a function merging the values of equal keys for use by
+// the tree's set operations. A nil merge keeps the value from the map.
+func resolver[K, V any](merge func(key K, a, b V) V) func(a, b Pair[K, V]) Pair[K, V] {
+	if merge == nil {
+		return nil
+	}
+	return func(a, b Pair[K, V]) Pair[K, V] {
+		return Pair[K, V]{Key: a.Key, Value: merge(a.Key, a.Value, b.Value)}
+	}
+}
+
+// Union returns a new map holding the elements with keys found in either
+// m or other. When a key is in both, merge is called with the key and both
+// values to compute the value kept; if merge is nil, the value from m is
+// kept. This takes O(n+m) time.
+func (m *Map[K, V]) Union(other *Map[K, V], merge func(key K, a, b V) V) *Map[K, V] {
+	return &Map[K, V]{t: *m.t.Union(&other.t, resolver(merge))}
+}
+
+// Intersection returns a new map holding the elements with keys found in
+// both m and other. The value kept is computed by merge as with Union.
+func (m *Map[K, V]) Intersection(other *Map[K, V], merge func(key K, a, b V) V) *Map[K, V] {
+	return &Map[K, V]{t: *m.t.Intersection(&other.t, resolver(merge))}
+}
+
+// Difference returns a new map holding the elements of m with keys that
+// are not found in other.
+func (m *Map[K, V]) Difference(other *Map[K, V]) *Map[K, V] {
+	return &Map[K, V]{t: *m.t.Difference(&other.t)}
+}
+
+// SymmetricDifference returns a new map holding the elements with keys
+// found in exactly one of m and other.
+func (m *Map[K, V]) SymmetricDifference(other *Map[K, V]) *Map[K, V] {
+	return &Map[K, V]{t: *m.t.SymmetricDifference(&other.t)}
+}
+
+// UnionWith adds the elements of other to m, as with Union.
+func (m *Map[K, V]) UnionWith(other *Map[K, V], merge func(key K, a, b V) V) {
+	m.t.UnionWith(&other.t, resolver(merge))
+}
+
+// IntersectWith removes the elements of m with keys that are not found
+// in other, as with Intersection.
+func (m *Map[K, V]) IntersectWith(other *Map[K, V], merge func(key K, a, b V) V) {
+	m.t.IntersectWith(&other.t, resolver(merge))
+}
+
+// DifferenceWith removes the elements of m with keys that are found in
+// other, as with Difference.
+func (m *Map[K, V]) DifferenceWith(other *Map[K, V]) {
+	m.t.DifferenceWith(&other.t)
+}
+
+// SymmetricDifferenceWith leaves m holding the elements with keys found in
+// exactly one of m and other, as with SymmetricDifference.
+func (m *Map[K, V]) SymmetricDifferenceWith(other *Map[K, V]) {
+	m.t.SymmetricDifferenceWith(&other.t)
+}
+
 // PrintMap prints the values of the Map to the given writer.
 func PrintMap[K, V any](m *Map[K, V], w io.Writer, f func(K, V) bool, itemSiz int) {
 	Print(&m.t, w, func(p Pair[K, V]) bool {
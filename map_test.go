@@ -104,3 +104,269 @@ func TestPrintMap(t *testing.T) {
 	t.Log(buf.String())
 	fmt.Println(buf.String())
 }
+
+func TestMapSeq(t *testing.T) {
+	m := NewMapOrdered[string, int]()
+
+	m.Add("c", 3)
+	m.Add("a", 1)
+	m.Add("b", 2)
+	m.Add("d", 4)
+
+	s := ""
+	x := 0
+	for k, v := range m.All() {
+		s += k
+		x += v
+	}
+
+	if s != "abcd" || x != 10 {
+		t.Errorf("All expected abcd/10, got %s/%d\n", s, x)
+	}
+
+	s = ""
+	for k := range m.AllKeys() {
+		if k == "c" {
+			break
+		}
+		s += k
+	}
+
+	if s != "ab" {
+		t.Errorf("AllKeys should stop at c, got %s\n", s)
+	}
+
+	x = 0
+	for v := range m.AllValues() {
+		x = x*10 + v
+	}
+
+	if x != 1234 {
+		t.Errorf("AllValues expected 1234, got %d\n", x)
+	}
+}
+
+func TestMapReverse(t *testing.T) {
+	m := NewMapOrdered[int, string]()
+
+	m.Add(2, "b")
+	m.Add(1, "a")
+	m.Add(3, "c")
+
+	s := ""
+	m.DoReverse(func(k int, v string) bool {
+		s += v
+		return true
+	})
+
+	if s != "cba" {
+		t.Errorf("DoReverse expected cba, got %s\n", s)
+	}
+
+	s = ""
+	for k, v := range m.Backward() {
+		if k == 1 {
+			break
+		}
+		s += v
+	}
+
+	if s != "cb" {
+		t.Errorf("Backward expected cb, got %s\n", s)
+	}
+
+	x := 4
+	for p := range m.IterReverse() {
+		if p.Key >= x {
+			t.Error("IterReverse expected", p, "to be <", x)
+		}
+		x = p.Key
+	}
+}
+
+func TestMapMinMax(t *testing.T) {
+	m := NewMapOrdered[string, int]()
+
+	m.Add("m", 1)
+	m.Add("a", 2)
+	m.Add("z", 3)
+
+	if p := m.Min(); p == nil || p.Key != "a" || p.Value != 2 {
+		t.Errorf("Min should be a/2: %v\n", p)
+	}
+
+	if p := m.PopMax(); p == nil || p.Key != "z" || p.Value != 3 || m.Len() != 2 {
+		t.Errorf("PopMax should be z/3 leaving 2: %v/%d\n", p, m.Len())
+	}
+
+	if p := m.PopMin(); p == nil || p.Key != "a" || m.Len() != 1 {
+		t.Errorf("PopMin should be a leaving 1: %v/%d\n", p, m.Len())
+	}
+
+	if p := m.Max(); p == nil || p.Key != "m" {
+		t.Errorf("Max should be m: %v\n", p)
+	}
+}
+
+func TestMapSet(t *testing.T) {
+	m := NewMapOrdered[string, int]()
+
+	old, replaced := m.Set("a", 1)
+
+	if replaced || old != 0 || m.Len() != 1 {
+		t.Errorf("First Set should insert: %v/%v/%d\n", old, replaced, m.Len())
+	}
+
+	old, replaced = m.Set("a", 2)
+
+	if !replaced || old != 1 || m.Len() != 1 {
+		t.Errorf("Second Set should replace 1: %v/%v/%d\n", old, replaced, m.Len())
+	}
+
+	if v := m.Find("a"); v == nil || *v != 2 {
+		t.Errorf("Find should locate the new value 2: %v\n", v)
+	}
+
+	// test Update
+
+	for _, k := range []string{"x", "y", "x", "x"} {
+		m.Update(k, func(old int, exists bool) int {
+			if exists {
+				return old + 1
+			}
+			return 100
+		})
+	}
+
+	if v := m.Find("x"); v == nil || *v != 102 {
+		t.Errorf("Update should count x to 102: %v\n", v)
+	}
+
+	if v := m.Update("y", func(old int, exists bool) int { return old * 2 }); v != 200 {
+		t.Errorf("Update should return the new value 200: %d\n", v)
+	}
+
+	if m.Len() != 3 {
+		t.Errorf("Map should have 3 elements: %d\n", m.Len())
+	}
+}
+
+func TestMapGet(t *testing.T) {
+	m := NewMapOrdered[string, []int]()
+
+	m.Add("a", []int{1})
+
+	v, ok := m.Get("a")
+	if !ok || len(v) != 1 || v[0] != 1 {
+		t.Errorf("Get should locate a: %v/%v\n", v, ok)
+	}
+
+	// the copy stays valid when the map is restructured
+	for j := 0; j < 100; j++ {
+		m.Add(fmt.Sprintf("%03d", j), nil)
+	}
+	m.Remove("a")
+
+	if len(v) != 1 || v[0] != 1 {
+		t.Errorf("Copy returned by Get should not change: %v\n", v)
+	}
+
+	if v, ok := m.Get("a"); ok || v != nil {
+		t.Errorf("Get after Remove should be nil/false: %v/%v\n", v, ok)
+	}
+
+	if !m.Contains("050") || m.Contains("a") {
+		t.Errorf("Contains should be true for 050 and false for a\n")
+	}
+}
+
+func TestMapRemoveIf(t *testing.T) {
+	m := NewMapOrdered[int, string]()
+
+	for j := 0; j < 10; j++ {
+		m.Add(j, fmt.Sprint(j%2))
+	}
+
+	if n := m.RemoveIf(func(k int, v string) bool { return v == "1" }); n != 5 || m.Len() != 5 {
+		t.Errorf("RemoveIf should remove 5 odd keys: %d/%d\n", n, m.Len())
+	}
+
+	if n := m.Retain(func(k int, v string) bool { return k < 6 }); n != 2 {
+		t.Errorf("Retain should remove 6 and 8: %d\n", n)
+	}
+
+	if n := m.RemoveRange(0, 2, Closed); n != 2 {
+		t.Errorf("RemoveRange(0, 2) should remove 2: %d\n", n)
+	}
+
+	if n := m.RemoveIndexRange(0, 1); n != 1 || m.Len() != 0 {
+		t.Errorf("RemoveIndexRange(0, 1) should empty the map: %d/%d\n", n, m.Len())
+	}
+}
+
+func TestMapClone(t *testing.T) {
+	m := NewMapOrdered[string, int]()
+
+	m.Set("a", 1)
+	m.Set("b", 2)
+
+	c := m.Clone()
+	c.Set("a", 10)
+	c.Update("b", func(old int, exists bool) int { return old * 10 })
+	m.Remove("b")
+
+	if v, _ := m.Get("a"); v != 1 || m.Len() != 1 {
+		t.Errorf("Original map should keep a=1 and lose b: %d/%d\n", v, m.Len())
+	}
+
+	if v, _ := c.Get("a"); v != 10 {
+		t.Errorf("Clone should have a=10: %d\n", v)
+	}
+
+	if v, _ := c.Get("b"); v != 20 {
+		t.Errorf("Clone should have b=20: %d\n", v)
+	}
+}
+
+func TestMapConcurrentModification(t *testing.T) {
+	m := NewMapOrdered[int, string]()
+	for i := 0; i < 100; i++ {
+		m.Add(i, fmt.Sprint(i))
+	}
+
+	if r := panics(func() {
+		for k := range m.All() {
+			m.Remove(k)
+		}
+	}); r != ErrConcurrentModification {
+		t.Errorf("Remove during All should panic: %v\n", r)
+	}
+
+	if r := panics(func() {
+		for k := range m.Backward() {
+			m.Add(k+1000, "")
+		}
+	}); r != ErrConcurrentModification {
+		t.Errorf("Add during Backward should panic: %v\n", r)
+	}
+
+	if r := panics(func() {
+		for k := range m.All() {
+			m.Set(k, "x") // replaces, so the structure is unchanged
+		}
+	}); r != nil {
+		t.Errorf("Set of existing keys during All should not panic: %v\n", r)
+	}
+
+	s := NewSyncMapOrdered[int, string](LockedIteration)
+	s.Set(1, "a")
+	s.Set(2, "b")
+	if r := panics(func() {
+		s.Do(func(k int, v string) bool {
+			s.m.Remove(k)
+			return true
+		})
+	}); r != ErrConcurrentModification {
+		t.Errorf("Remove during SyncMap.Do should panic: %v\n", r)
+	}
+}
@@ -0,0 +1,135 @@
+package avltree
+
+import (
+	"cmp"
+	"iter"
+)
+
+// MultiMap is a sorted map that can hold several values per key.
+// The values for a key are kept in the order they were added.
+type MultiMap[K, V any] struct {
+	t Tree[Pair[K, V]]
+}
+
+// NewMultiMap returns an initialized multimap.
+func NewMultiMap[K, V any](c func(K, K) int) *MultiMap[K, V] {
+	return &MultiMap[K, V]{
+		t: Tree[Pair[K, V]]{
+			compare: func(v1, v2 Pair[K, V]) int {
+				return c(v1.Key, v2.Key)
+			},
+			treeFlags: AllowDuplicates | DuplicatesFIFO,
+		},
+	}
+}
+
+// NewMultiMapOrdered returns an initialized multimap using ordered types.
+func NewMultiMapOrdered[K cmp.Ordered, V any]() *MultiMap[K, V] {
+	return &MultiMap[K, V]{
+		t: Tree[Pair[K, V]]{
+			compare: func(v1, v2 Pair[K, V]) int {
+				return cmp.Compare(v1.Key, v2.Key)
+			},
+			treeFlags: AllowDuplicates | DuplicatesFIFO,
+		},
+	}
+}
+
+// Clear removes all elements from the multimap.
+func (m *MultiMap[K, V]) Clear() {
+	m.t.Clear()
+}
+
+// Len returns the number of values in the multimap.
+func (m *MultiMap[K, V]) Len() int {
+	return m.t.Len()
+}
+
+// Add adds a value for the key, keeping any values already stored
+// for it.
+func (m *MultiMap[K, V]) Add(k K, v V) {
+	m.t.Add(Pair[K, V]{Key: k, Value: v})
+}
+
+// Contains returns true if there is at least one value for the key.
+func (m *MultiMap[K, V]) Contains(k K) bool {
+	return m.t.Contains(Pair[K, V]{Key: k})
+}
+
+// CountKey returns the number of values stored for the key in O(log n) time.
+func (m *MultiMap[K, V]) CountKey(k K) int {
+	return m.t.CountEqual(Pair[K, V]{Key: k})
+}
+
+// GetAll returns an iterator over the values stored for the key.
+func (m *MultiMap[K, V]) GetAll(k K) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for p := range m.t.FindAll(Pair[K, V]{Key: k}) {
+			if !yield(p.Value) {
+				return
+			}
+		}
+	}
+}
+
+// RemoveAll removes all the values stored for the key, returning how
+// many were removed. This takes O(log n) time.
+func (m *MultiMap[K, V]) RemoveAll(k K) int {
+	return m.t.RemoveAll(Pair[K, V]{Key: k})
+}
+
+// RemoveOne removes the oldest value stored for the key for which match
+// returns true. The removed value is returned, along with true if one
+// was found.
+func (m *MultiMap[K, V]) RemoveOne(k K, match func(V) bool) (V, bool) {
+	p := m.t.RemoveFunc(Pair[K, V]{Key: k}, func(p Pair[K, V]) bool {
+		return match(p.Value)
+	})
+	if p != nil {
+		return p.Value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Do calls function f for each key and value of the multimap, in key order.
+// The function should not change the structure of the multimap underfoot.
+func (m *MultiMap[K, V]) Do(f func(K, V) bool) {
+	if f != nil {
+		m.t.Do(func(p Pair[K, V]) bool {
+			return f(p.Key, p.Value)
+		})
+	}
+}
+
+// All returns an iterator over the keys and values of the multimap,
+// in key order.
+func (m *MultiMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m.Do(yield)
+	}
+}
+
+// Groups returns an iterator over each distinct key of the multimap,
+// in order, along with all the values stored for it.
+func (m *MultiMap[K, V]) Groups() iter.Seq2[K, []V] {
+	return func(yield func(K, []V) bool) {
+		var key K
+		var values []V
+		proceed := true
+		m.t.Do(func(p Pair[K, V]) bool {
+			if values != nil && m.t.compare(Pair[K, V]{Key: key}, p) != 0 {
+				if proceed = yield(key, values); !proceed {
+					return false
+				}
+				values = nil
+			}
+			key = p.Key
+			values = append(values, p.Value)
+			return true
+		})
+		if proceed && values != nil {
+			yield(key, values)
+		}
+	}
+}
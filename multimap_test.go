@@ -0,0 +1,124 @@
+package avltree
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMultiMap(t *testing.T) {
+	m := NewMultiMapOrdered[string, int]()
+
+	if m.Len() != 0 || m.Contains("a") {
+		t.Errorf("Initialized multimap should be empty: %d\n", m.Len())
+	}
+
+	for j := 0; j < 10; j++ {
+		m.Add("a", j)
+		m.Add("b", j*10)
+	}
+	m.Add("c", 100)
+
+	if m.Len() != 21 {
+		t.Errorf("Multimap should have 21 values: %d\n", m.Len())
+	}
+
+	if n := m.CountKey("a"); n != 10 {
+		t.Errorf("CountKey(a) should be 10: %d\n", n)
+	}
+
+	if n := m.CountKey("x"); n != 0 {
+		t.Errorf("CountKey(x) should be 0: %d\n", n)
+	}
+
+	x := 0
+	for v := range m.GetAll("b") {
+		x += v
+	}
+
+	if x != 450 {
+		t.Errorf("GetAll(b) should add up to 450: %d\n", x)
+	}
+
+	// test RemoveOne
+
+	v, ok := m.RemoveOne("b", func(v int) bool { return v == 30 })
+
+	if !ok || v != 30 || m.CountKey("b") != 9 {
+		t.Errorf("RemoveOne should remove 30 from b: %v/%v/%d\n", v, ok, m.CountKey("b"))
+	}
+
+	if _, ok := m.RemoveOne("b", func(v int) bool { return v == 30 }); ok {
+		t.Errorf("RemoveOne should not find 30 again\n")
+	}
+
+	if _, ok := m.RemoveOne("c", func(v int) bool { return v == 30 }); ok {
+		t.Errorf("RemoveOne should not look outside the key\n")
+	}
+
+	// test RemoveAll
+
+	if n := m.RemoveAll("a"); n != 10 || m.Len() != 10 || m.Contains("a") {
+		t.Errorf("RemoveAll(a) should remove 10 values: %d/%d\n", n, m.Len())
+	}
+	checkTree(t, &m.t)
+
+	if n := m.RemoveAll("a"); n != 0 {
+		t.Errorf("RemoveAll(a) again should remove nothing: %d\n", n)
+	}
+}
+
+func TestMultiMapGroups(t *testing.T) {
+	m := NewMultiMapOrdered[int, string]()
+
+	for _, s := range []string{"x", "y", "z"} {
+		m.Add(2, s)
+		m.Add(1, s)
+	}
+	m.Add(3, "w")
+
+	keys := []int{}
+	for k, values := range m.Groups() {
+		keys = append(keys, k)
+		want := 3
+		if k == 3 {
+			want = 1
+		}
+		if len(values) != want {
+			t.Errorf("Group %d should have %d values: %v\n", k, want, values)
+		}
+	}
+
+	if !slices.Equal(keys, []int{1, 2, 3}) {
+		t.Errorf("Groups should yield keys 1,2,3: %v\n", keys)
+	}
+
+	n := 0
+	for range m.Groups() {
+		n++
+		break
+	}
+
+	if n != 1 {
+		t.Errorf("Groups should stop after the first group: %d\n", n)
+	}
+}
+
+func TestMultiMapOrder(t *testing.T) {
+	m := NewMultiMapOrdered[int, int]()
+
+	for j := 0; j < 100; j++ {
+		m.Add(j%3, j)
+	}
+
+	prev := -1
+	for v := range m.GetAll(1) {
+		if v <= prev {
+			t.Errorf("GetAll should yield values in insertion order: %d after %d\n", v, prev)
+		}
+		prev = v
+	}
+
+	if v, ok := m.RemoveOne(2, func(v int) bool { return v > 10 }); !ok || v != 11 {
+		t.Errorf("RemoveOne should remove the oldest match 11: %v/%v\n", v, ok)
+	}
+}
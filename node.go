@@ -21,6 +21,27 @@ type treeNode[T any] struct {
 
 	// The balance factor of this node.
 	bal byte
+
+	// The owner allowed to change this node in place.
+	owner *owner
+}
+
+// owner identifies a tree allowed to change nodes in place. Nodes with
+// a different owner may be shared with other trees, and are copied
+// before they are changed.
+type owner struct {
+	_ byte // distinct owners need distinct addresses
+}
+
+// mutable returns the node itself if it belongs to o, or otherwise
+// a copy of it that does.
+func (n *treeNode[T]) mutable(o *owner) *treeNode[T] {
+	if n == nil || n.owner == o {
+		return n
+	}
+	c := *n
+	c.owner = o
+	return &c
 }
 
 // leftSize returns the size of the left subtree
@@ -246,3 +246,128 @@ func TestObjectTree(t *testing.T) {
 	})
 
 }
+
+func TestObjectReplaceOrInsert(t *testing.T) {
+	byKey := func(a, b MyObject) int {
+		if a.Key < b.Key {
+			return -1
+		}
+		if a.Key > b.Key {
+			return 1
+		}
+		return 0
+	}
+
+	tree := New[MyObject](byKey, 0)
+
+	old, replaced := tree.ReplaceOrInsert(MyObject{"foo", "bar"})
+
+	if replaced || old.Key != "" || tree.Len() != 1 {
+		t.Errorf("First ReplaceOrInsert should insert: %v/%v/%d\n", old, replaced, tree.Len())
+	}
+
+	old, replaced = tree.ReplaceOrInsert(MyObject{"foo", "baz"})
+
+	if !replaced || old.Value != "bar" || tree.Len() != 1 {
+		t.Errorf("Second ReplaceOrInsert should replace bar: %v/%v/%d\n", old, replaced, tree.Len())
+	}
+
+	if v := tree.Find(MyObject{"foo", ""}); v == nil || v.Value != "baz" {
+		t.Errorf("Find should locate the replacement baz: %v\n", v)
+	}
+
+	// with duplicates, an equal element is replaced rather than added
+
+	tree = New[MyObject](byKey, AllowDuplicates)
+	tree.Add(MyObject{"foo", "1"})
+	tree.Add(MyObject{"foo", "2"})
+
+	_, replaced = tree.ReplaceOrInsert(MyObject{"foo", "3"})
+
+	if !replaced || tree.Len() != 2 {
+		t.Errorf("ReplaceOrInsert with duplicates should replace: %v/%d\n", replaced, tree.Len())
+	}
+}
+
+func TestObjectDuplicateOrder(t *testing.T) {
+	byKey := func(a, b MyObject) int {
+		if a.Key < b.Key {
+			return -1
+		}
+		if a.Key > b.Key {
+			return 1
+		}
+		return 0
+	}
+
+	tests := []struct {
+		flags byte
+		order string
+	}{
+		{AllowDuplicates, "4321"},
+		{AllowDuplicates | DuplicatesFIFO, "1234"},
+	}
+
+	for _, tc := range tests {
+		tree := New[MyObject](byKey, tc.flags)
+		tree.Add(MyObject{"b", "x"})
+		for _, v := range []string{"1", "2", "3", "4"} {
+			tree.Add(MyObject{"a", v})
+			tree.Add(MyObject{"c", v})
+		}
+
+		x := ""
+		for v := range tree.FindAll(MyObject{Key: "a"}) {
+			x += v.Value
+		}
+
+		if x != tc.order {
+			t.Errorf("Flags %d should order duplicates as %s: %s\n", tc.flags, tc.order, x)
+		}
+
+		// Remove takes the first in order: newest, or oldest with FIFO
+		x = ""
+		for tree.Contains(MyObject{Key: "c"}) {
+			x += tree.Remove(MyObject{Key: "c"}).Value
+		}
+
+		if x != tc.order {
+			t.Errorf("Flags %d should remove duplicates as %s: %s\n", tc.flags, tc.order, x)
+		}
+	}
+}
+
+func TestObjectRemoveFunc(t *testing.T) {
+	tree := New[MyObject](func(a, b MyObject) int {
+		if a.Key < b.Key {
+			return -1
+		}
+		if a.Key > b.Key {
+			return 1
+		}
+		return 0
+	}, AllowDuplicates)
+
+	for j := 0; j < 20; j++ {
+		tree.Add(MyObject{fmt.Sprintf("%d", j%4), fmt.Sprintf("id%d", j)})
+	}
+
+	v := tree.RemoveFunc(MyObject{Key: "1"}, func(o MyObject) bool { return o.Value == "id9" })
+
+	if v == nil || v.Key != "1" || v.Value != "id9" || tree.Len() != 19 {
+		t.Errorf("RemoveFunc should remove 1/id9: %v/%d\n", v, tree.Len())
+	}
+
+	// a matching value under another key is not removed
+	v = tree.RemoveFunc(MyObject{Key: "2"}, func(o MyObject) bool { return o.Value == "id5" })
+
+	if v != nil || tree.Len() != 19 {
+		t.Errorf("RemoveFunc should not find id5 under key 2: %v/%d\n", v, tree.Len())
+	}
+
+	for x := range tree.FindAll(MyObject{Key: "1"}) {
+		if x.Value == "id9" {
+			t.Errorf("RemoveFunc should have removed id9\n")
+		}
+	}
+}
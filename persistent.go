@@ -0,0 +1,158 @@
+package avltree
+
+import (
+	"cmp"
+	"iter"
+)
+
+// Persistent is an immutable tree. Adding or removing elements returns a
+// new version that shares its unchanged nodes with the old one, so every
+// version stays valid and may be read from many goroutines without
+// locking, while new versions are created.
+type Persistent[T any] struct {
+	t Tree[T]
+}
+
+// NewPersistent returns an empty persistent tree.
+func NewPersistent[T any](c func(T, T) int, flags byte) *Persistent[T] {
+	return &Persistent[T]{
+		t: Tree[T]{
+			compare:   c,
+			treeFlags: flags,
+		},
+	}
+}
+
+// NewPersistentOrdered returns an empty persistent tree using ordered types.
+func NewPersistentOrdered[T cmp.Ordered](flags byte) *Persistent[T] {
+	return &Persistent[T]{
+		t: Tree[T]{
+			compare:   cmp.Compare[T],
+			treeFlags: flags,
+		},
+	}
+}
+
+// edit returns a new version sharing the nodes of p. Changes to it copy
+// the nodes they touch, leaving p as it was.
+func (p *Persistent[T]) edit() *Persistent[T] {
+	n := &Persistent[T]{t: p.t}
+	n.t.owner = new(owner)
+	return n
+}
+
+// Add returns a version with the item added. If the item is a duplicate
+// and the tree does not allow duplicates, p itself is returned.
+func (p *Persistent[T]) Add(o T) *Persistent[T] {
+	n := p.edit()
+	if _, dupe := n.t.Add(o); dupe {
+		return p
+	}
+	return n
+}
+
+// ReplaceOrInsert returns a version with the item added, replacing an
+// existing item that compares equal to it.
+func (p *Persistent[T]) ReplaceOrInsert(o T) *Persistent[T] {
+	n := p.edit()
+	n.t.ReplaceOrInsert(o)
+	return n
+}
+
+// Remove returns a version without the element matching the given value.
+// If there is no such element, p itself is returned.
+func (p *Persistent[T]) Remove(o T) *Persistent[T] {
+	n := p.edit()
+	if n.t.Remove(o) == nil {
+		return p
+	}
+	return n
+}
+
+// RemoveAt returns a version without the element at the given index.
+// If the index is out of range, p itself is returned.
+func (p *Persistent[T]) RemoveAt(index int) *Persistent[T] {
+	n := p.edit()
+	if n.t.RemoveAt(index) == nil {
+		return p
+	}
+	return n
+}
+
+// Len returns the number of elements in this version.
+func (p *Persistent[T]) Len() int {
+	return p.t.Len()
+}
+
+// Get returns a copy of the element matching the given key value, and
+// true if it was found.
+func (p *Persistent[T]) Get(key T) (T, bool) {
+	return p.t.Get(key)
+}
+
+// Contains returns true if an element matching the given key value
+// is in this version.
+func (p *Persistent[T]) Contains(key T) bool {
+	return p.t.Contains(key)
+}
+
+// At returns a copy of the element at the given index, and true if the
+// index is valid.
+func (p *Persistent[T]) At(index int) (T, bool) {
+	return value(p.t.At(index))
+}
+
+// Min returns a copy of the smallest element, and false if there is none.
+func (p *Persistent[T]) Min() (T, bool) {
+	return value(p.t.Min())
+}
+
+// Max returns a copy of the largest element, and false if there is none.
+func (p *Persistent[T]) Max() (T, bool) {
+	return value(p.t.Max())
+}
+
+// Rank returns the number of elements strictly less than key.
+func (p *Persistent[T]) Rank(key T) int {
+	return p.t.Rank(key)
+}
+
+// IndexOf returns the index of the element matching key. If there is no
+// matching element, -1 and false are returned.
+func (p *Persistent[T]) IndexOf(key T) (int, bool) {
+	return p.t.IndexOf(key)
+}
+
+// Do calls function f for each element of this version, in order.
+func (p *Persistent[T]) Do(f func(T) bool) {
+	p.t.Do(f)
+}
+
+// DoReverse calls function f for each element of this version, in
+// reverse order.
+func (p *Persistent[T]) DoReverse(f func(T) bool) {
+	p.t.DoReverse(f)
+}
+
+// All returns an iterator over all the elements of this version, in order.
+func (p *Persistent[T]) All() iter.Seq[T] {
+	return p.t.All()
+}
+
+// Backward returns an iterator over all the elements of this version,
+// in reverse order.
+func (p *Persistent[T]) Backward() iter.Seq[T] {
+	return p.t.Backward()
+}
+
+// Range returns an iterator over the elements of this version between lo
+// and hi, in order. The bounds determine whether lo and hi themselves are
+// included.
+func (p *Persistent[T]) Range(lo, hi T, bounds Bounds) iter.Seq[T] {
+	return p.t.Range(lo, hi, bounds)
+}
+
+// Data returns all the elements of this version as a slice.
+func (p *Persistent[T]) Data() []T {
+	return p.t.Data()
+}
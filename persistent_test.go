@@ -0,0 +1,112 @@
+package avltree
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// countShared returns the number of nodes of b that are also in a.
+func countShared[T any](a, b *treeNode[T]) int {
+	nodes := make(map[*treeNode[T]]bool)
+	var walk func(n *treeNode[T], f func(*treeNode[T]))
+	walk = func(n *treeNode[T], f func(*treeNode[T])) {
+		if n != nil {
+			f(n)
+			walk(n.left, f)
+			walk(n.right, f)
+		}
+	}
+	walk(a, func(n *treeNode[T]) { nodes[n] = true })
+	shared := 0
+	walk(b, func(n *treeNode[T]) {
+		if nodes[n] {
+			shared++
+		}
+	})
+	return shared
+}
+
+func TestPersistent(t *testing.T) {
+	p := NewPersistentOrdered[int](0)
+
+	if p.Len() != 0 {
+		t.Errorf("Initialized persistent tree should be empty: %d\n", p.Len())
+	}
+
+	// keep every version along with what it should hold
+	versions := []*Persistent[int]{p}
+	want := [][]int{nil}
+	var cur []int
+
+	for j := 0; j < 2000; j++ {
+		v := rand.Intn(500)
+		if rand.Intn(3) == 0 {
+			p = p.Remove(v)
+			if i, ok := slices.BinarySearch(cur, v); ok {
+				cur = slices.Delete(slices.Clone(cur), i, i+1)
+			}
+		} else {
+			p = p.Add(v)
+			if i, ok := slices.BinarySearch(cur, v); !ok {
+				cur = slices.Insert(slices.Clone(cur), i, v)
+			}
+		}
+		versions = append(versions, p)
+		want = append(want, cur)
+	}
+
+	for i, v := range versions {
+		if !slices.Equal(v.Data(), want[i]) {
+			t.Fatalf("Version %d changed: %v, expected %v\n", i, v.Data(), want[i])
+		}
+		checkTree(t, &v.t)
+	}
+}
+
+func TestPersistentSharing(t *testing.T) {
+	p := NewPersistentOrdered[int](0)
+
+	for j := 0; j < 1000; j++ {
+		p = p.Add(j)
+	}
+
+	q := p.Add(1000)
+
+	if p.Len() != 1000 || q.Len() != 1001 {
+		t.Errorf("Versions should have 1000/1001 elements: %d/%d\n", p.Len(), q.Len())
+	}
+
+	// only the path to the new node is copied
+	if n := countShared(p.t.root, q.t.root); n < 1000-2*p.t.Height() {
+		t.Errorf("Add should share most nodes, shared %d\n", n)
+	}
+
+	r := q.Remove(500)
+
+	if q.Len() != 1001 || r.Len() != 1000 || !q.Contains(500) || r.Contains(500) {
+		t.Errorf("Remove should only change the new version: %d/%d\n", q.Len(), r.Len())
+	}
+
+	if n := countShared(q.t.root, r.t.root); n < 1000-4*q.t.Height() {
+		t.Errorf("Remove should share most nodes, shared %d\n", n)
+	}
+
+	if p.Add(5) != p || p.Remove(5000) != p || p.RemoveAt(5000) != p {
+		t.Errorf("Changes that do nothing should return the same version\n")
+	}
+
+	if v, ok := r.At(500); !ok || v != 501 {
+		t.Errorf("At(500) should be 501: %v/%v\n", v, ok)
+	}
+
+	s := r.RemoveAt(0).ReplaceOrInsert(1)
+
+	if v, ok := s.Min(); !ok || v != 1 || s.Len() != 999 {
+		t.Errorf("Min should be 1 with 999 elements: %v/%d\n", v, s.Len())
+	}
+
+	if v, ok := r.Min(); !ok || v != 0 {
+		t.Errorf("Earlier version Min should still be 0: %v\n", v)
+	}
+}
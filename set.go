@@ -0,0 +1,145 @@
+package avltree
+
+import (
+	"cmp"
+	"iter"
+)
+
+// Set is a sorted set of unique values.
+type Set[T any] struct {
+	t Tree[T]
+}
+
+// NewSet returns an initialized set.
+func NewSet[T any](c func(T, T) int) *Set[T] {
+	return &Set[T]{
+		t: Tree[T]{
+			compare: c,
+		},
+	}
+}
+
+// NewSetOrdered returns an initialized set using ordered types.
+func NewSetOrdered[T cmp.Ordered]() *Set[T] {
+	return &Set[T]{
+		t: Tree[T]{
+			compare: cmp.Compare[T],
+		},
+	}
+}
+
+// Clear removes all elements from the set, keeping the
+// current compare function.
+func (s *Set[T]) Clear() {
+	s.t.Clear()
+}
+
+// Len returns the number of elements in the set.
+func (s *Set[T]) Len() int {
+	return s.t.Len()
+}
+
+// Insert adds a value to the set, returning true if it was not
+// already present.
+func (s *Set[T]) Insert(v T) bool {
+	_, dupe := s.t.Add(v)
+	return !dupe
+}
+
+// Delete removes a value from the set, returning true if it was present.
+func (s *Set[T]) Delete(v T) bool {
+	return s.t.Remove(v) != nil
+}
+
+// Has returns true if the value is in the set.
+func (s *Set[T]) Has(v T) bool {
+	return s.t.Contains(v)
+}
+
+// At returns the value at the given index, and true if the index is valid.
+func (s *Set[T]) At(index int) (T, bool) {
+	return value(s.t.At(index))
+}
+
+// Min returns the smallest value in the set, and false if the set is empty.
+func (s *Set[T]) Min() (T, bool) {
+	return value(s.t.Min())
+}
+
+// Max returns the largest value in the set, and false if the set is empty.
+func (s *Set[T]) Max() (T, bool) {
+	return value(s.t.Max())
+}
+
+// Do calls function f for each value of the set, in order.
+// The function should not change the structure of the set underfoot.
+func (s *Set[T]) Do(f func(T) bool) {
+	s.t.Do(f)
+}
+
+// All returns an iterator over the values of the set, in order.
+func (s *Set[T]) All() iter.Seq[T] {
+	return s.t.All()
+}
+
+// Backward returns an iterator over the values of the set, in reverse order.
+func (s *Set[T]) Backward() iter.Seq[T] {
+	return s.t.Backward()
+}
+
+// Range returns an iterator over the values of the set between lo and hi,
+// in order. The bounds determine whether lo and hi themselves are included.
+func (s *Set[T]) Range(lo, hi T, bounds Bounds) iter.Seq[T] {
+	return s.t.Range(lo, hi, bounds)
+}
+
+// Values returns all the values as a slice.
+func (s *Set[T]) Values() []T {
+	return s.t.Data()
+}
+
+// IsSubset returns true if every value of s is in other.
+func (s *Set[T]) IsSubset(other *Set[T]) bool {
+	if s.Len() > other.Len() {
+		return false
+	}
+	subset := true
+	s.t.Do(func(v T) bool {
+		subset = other.t.Contains(v)
+		return subset
+	})
+	return subset
+}
+
+// IsSuperset returns true if every value of other is in s.
+func (s *Set[T]) IsSuperset(other *Set[T]) bool {
+	return other.IsSubset(s)
+}
+
+// Equal returns true if s and other hold the same values.
+func (s *Set[T]) Equal(other *Set[T]) bool {
+	return s.Len() == other.Len() && s.IsSubset(other)
+}
+
+// Union returns a new set holding the values found in either s or other.
+func (s *Set[T]) Union(other *Set[T]) *Set[T] {
+	return &Set[T]{t: *s.t.Union(&other.t, nil)}
+}
+
+// Intersection returns a new set holding the values found in both
+// s and other.
+func (s *Set[T]) Intersection(other *Set[T]) *Set[T] {
+	return &Set[T]{t: *s.t.Intersection(&other.t, nil)}
+}
+
+// Difference returns a new set holding the values of s that are not
+// found in other.
+func (s *Set[T]) Difference(other *Set[T]) *Set[T] {
+	return &Set[T]{t: *s.t.Difference(&other.t)}
+}
+
+// SymmetricDifference returns a new set holding the values found in
+// exactly one of s and other.
+func (s *Set[T]) SymmetricDifference(other *Set[T]) *Set[T] {
+	return &Set[T]{t: *s.t.SymmetricDifference(&other.t)}
+}
@@ -0,0 +1,96 @@
+package avltree
+
+import "testing"
+
+func TestSet(t *testing.T) {
+	s := NewSetOrdered[int]()
+
+	if s.Len() != 0 {
+		t.Errorf("Initialized set should be empty: %d\n", s.Len())
+	}
+
+	if _, ok := s.Min(); ok {
+		t.Errorf("Min of empty set should fail\n")
+	}
+
+	if !s.Insert(5) || !s.Insert(3) || !s.Insert(8) {
+		t.Errorf("Insert of new values should return true\n")
+	}
+
+	if s.Insert(5) || s.Len() != 3 {
+		t.Errorf("Insert of existing value should return false: %d\n", s.Len())
+	}
+
+	if !s.Has(3) || s.Has(4) {
+		t.Errorf("Has should be true for 3 and false for 4\n")
+	}
+
+	if v, ok := s.Min(); !ok || v != 3 {
+		t.Errorf("Min should be 3: %v/%v\n", v, ok)
+	}
+
+	if v, ok := s.Max(); !ok || v != 8 {
+		t.Errorf("Max should be 8: %v/%v\n", v, ok)
+	}
+
+	if !s.Delete(3) || s.Delete(3) || s.Len() != 2 {
+		t.Errorf("Delete should remove 3 once: %d\n", s.Len())
+	}
+
+	x := 0
+	for v := range s.All() {
+		x = x*10 + v
+	}
+
+	if x != 58 {
+		t.Errorf("All should yield 5,8: %d\n", x)
+	}
+
+	s.Clear()
+
+	if s.Len() != 0 {
+		t.Errorf("Cleared set should be empty: %d\n", s.Len())
+	}
+}
+
+func TestSetRelations(t *testing.T) {
+	a := NewSetOrdered[string]()
+	b := NewSetOrdered[string]()
+
+	for _, v := range []string{"a", "b", "c"} {
+		a.Insert(v)
+	}
+	for _, v := range []string{"a", "b", "c", "d"} {
+		b.Insert(v)
+	}
+
+	if !a.IsSubset(b) || b.IsSubset(a) {
+		t.Errorf("a should be a subset of b, but not the reverse\n")
+	}
+
+	if !b.IsSuperset(a) || a.IsSuperset(b) {
+		t.Errorf("b should be a superset of a, but not the reverse\n")
+	}
+
+	if a.Equal(b) || !a.Equal(a) {
+		t.Errorf("a should only equal itself\n")
+	}
+
+	if u := a.Union(b); !u.Equal(b) {
+		t.Errorf("Union should equal b: %v\n", u.Values())
+	}
+
+	if i := a.Intersection(b); !i.Equal(a) {
+		t.Errorf("Intersection should equal a: %v\n", i.Values())
+	}
+
+	if d := b.Difference(a); d.Len() != 1 || !d.Has("d") {
+		t.Errorf("Difference should be d: %v\n", d.Values())
+	}
+
+	b.Delete("a")
+
+	if d := a.SymmetricDifference(b).Values(); len(d) != 2 || d[0] != "a" || d[1] != "d" {
+		t.Errorf("SymmetricDifference should be a,d: %v\n", d)
+	}
+}
@@ -0,0 +1,404 @@
+package avltree
+
+import (
+	"cmp"
+	"iter"
+	"sync"
+)
+
+// SyncMode selects how SyncTree and SyncMap iterate.
+type SyncMode byte
+
+// iteration modes
+const (
+	LockedIteration   SyncMode = iota // hold the read lock while iterating
+	SnapshotIteration                 // iterate a snapshot taken when iteration starts
+)
+
+// SyncTree is a tree that is safe for concurrent use by multiple
+// goroutines. Values are returned as copies rather than pointers into
+// the tree.
+//
+// With LockedIteration, writers wait until an iteration finishes. The
+// iteration function must not call any method of the SyncTree, not even
+// one that only reads it: the read lock is held during iteration, and
+// taking it again deadlocks if a writer is waiting. With SnapshotIteration,
+// the tree is cloned in constant time when iteration starts, and the
+// function may use and change the tree without affecting the iteration.
+type SyncTree[T any] struct {
+	mu   sync.RWMutex
+	t    Tree[T]
+	mode SyncMode
+}
+
+// NewSyncTree returns an initialized synchronized tree.
+func NewSyncTree[T any](c func(T, T) int, flags byte, mode SyncMode) *SyncTree[T] {
+	return &SyncTree[T]{
+		t: Tree[T]{
+			compare:   c,
+			treeFlags: flags,
+		},
+		mode: mode,
+	}
+}
+
+// NewSyncTreeOrdered returns an initialized synchronized tree using
+// ordered types.
+func NewSyncTreeOrdered[T cmp.Ordered](flags byte, mode SyncMode) *SyncTree[T] {
+	return NewSyncTree(cmp.Compare[T], flags, mode)
+}
+
+// Clear removes all elements from the tree.
+func (s *SyncTree[T]) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.t.Clear()
+}
+
+// Len returns the number of elements in the tree.
+func (s *SyncTree[T]) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.t.Len()
+}
+
+// Add adds an item to the tree, returning the added (or duplicate) item
+// and a flag indicating whether the item is the duplicate that was found.
+func (s *SyncTree[T]) Add(o T) (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, dupe := s.t.Add(o)
+	return *v, dupe
+}
+
+// GetOrAdd returns the element matching o if there is one, along with
+// true. Otherwise it adds o and returns it along with false.
+func (s *SyncTree[T]) GetOrAdd(o T) (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if v := s.t.Find(o); v != nil {
+		return *v, true
+	}
+	s.t.Add(o)
+	return o, false
+}
+
+// ReplaceOrInsert adds an item to the tree, replacing an existing item
+// that compares equal to it. If an item was replaced, it is returned
+// along with true.
+func (s *SyncTree[T]) ReplaceOrInsert(o T) (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.t.ReplaceOrInsert(o)
+}
+
+// CompareAndSwap replaces the element that is equal to old using == with
+// new. If the tree allows duplicates, elements that only compare equal to
+// old are left alone. If new compares equal to old, it takes the place of
+// old; otherwise the swap is not made if new would be a duplicate the tree
+// does not allow. It reports whether the swap was made. It panics if T is
+// not comparable.
+func (s *SyncTree[T]) CompareAndSwap(old, new T) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	index := s.t.indexFunc(old, func(v T) bool { return any(v) == any(old) })
+	if index < 0 {
+		return false
+	}
+	if s.t.compare(new, old) == 0 {
+		s.t.replaceAt(index, new)
+		return true
+	}
+	if (s.t.treeFlags&AllowDuplicates) == 0 && s.t.Find(new) != nil {
+		return false
+	}
+	s.t.RemoveAt(index)
+	s.t.Add(new)
+	return true
+}
+
+// Update sets the element matching key to the result of f, which is
+// called with the element and true if there is one, or key and false
+// if there is not. The result must compare equal to key; if it does not,
+// Update panics and the tree is left unchanged. The new element is
+// returned.
+func (s *SyncTree[T]) Update(key T, f func(old T, exists bool) T) T {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return *s.t.upsert(key, func(old T, exists bool) T {
+		v := f(old, exists)
+		if s.t.compare(v, key) != 0 {
+			panic("avltree: Update result does not compare equal to key")
+		}
+		return v
+	})
+}
+
+// Remove removes the element matching the given value, returning it
+// along with true if it was found.
+func (s *SyncTree[T]) Remove(o T) (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return value(s.t.Remove(o))
+}
+
+// RemoveAt removes the element at the given index, returning it along
+// with true if the index was valid.
+func (s *SyncTree[T]) RemoveAt(index int) (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return value(s.t.RemoveAt(index))
+}
+
+// Get returns a copy of the element matching the given key value, and
+// true if it was found.
+func (s *SyncTree[T]) Get(key T) (T, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.t.Get(key)
+}
+
+// Contains returns true if an element matching the given key value
+// is in the tree.
+func (s *SyncTree[T]) Contains(key T) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.t.Contains(key)
+}
+
+// At returns a copy of the element at the given index, and true if the
+// index is valid.
+func (s *SyncTree[T]) At(index int) (T, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return value(s.t.At(index))
+}
+
+// Min returns a copy of the smallest element, and false if there is none.
+func (s *SyncTree[T]) Min() (T, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return value(s.t.Min())
+}
+
+// Max returns a copy of the largest element, and false if there is none.
+func (s *SyncTree[T]) Max() (T, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return value(s.t.Max())
+}
+
+// Snapshot returns an unsynchronized copy of the tree, made in constant
+// time.
+func (s *SyncTree[T]) Snapshot() *Tree[T] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.t.Clone()
+}
+
+// walk calls f with the tree to iterate, according to the iteration mode.
+func (s *SyncTree[T]) walk(f func(t *Tree[T])) {
+	if s.mode == SnapshotIteration {
+		f(s.Snapshot())
+		return
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	f(&s.t)
+}
+
+// Do calls function f for each element of the tree, in order.
+func (s *SyncTree[T]) Do(f func(T) bool) {
+	s.walk(func(t *Tree[T]) {
+		t.Do(f)
+	})
+}
+
+// All returns an iterator over all the elements of the tree, in order.
+func (s *SyncTree[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		s.walk(func(t *Tree[T]) {
+			t.Do(yield)
+		})
+	}
+}
+
+// Backward returns an iterator over all the elements of the tree,
+// in reverse order.
+func (s *SyncTree[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		s.walk(func(t *Tree[T]) {
+			t.DoReverse(yield)
+		})
+	}
+}
+
+// Range returns an iterator over the elements of the tree between lo
+// and hi, in order. The bounds determine whether lo and hi themselves
+// are included.
+func (s *SyncTree[T]) Range(lo, hi T, bounds Bounds) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		s.walk(func(t *Tree[T]) {
+			t.DoBetween(lo, hi, bounds, yield)
+		})
+	}
+}
+
+// SyncMap is a map that is safe for concurrent use by multiple
+// goroutines. Iteration works as described for SyncTree; in particular,
+// with LockedIteration the iteration function must not call any method
+// of the SyncMap.
+type SyncMap[K, V any] struct {
+	mu   sync.RWMutex
+	m    Map[K, V]
+	mode SyncMode
+}
+
+// NewSyncMap returns an initialized synchronized map.
+func NewSyncMap[K, V any](c func(K, K) int, mode SyncMode) *SyncMap[K, V] {
+	return &SyncMap[K, V]{
+		m:    *NewMap[K, V](c),
+		mode: mode,
+	}
+}
+
+// NewSyncMapOrdered returns an initialized synchronized map using
+// ordered types.
+func NewSyncMapOrdered[K cmp.Ordered, V any](mode SyncMode) *SyncMap[K, V] {
+	return &SyncMap[K, V]{
+		m:    *NewMapOrdered[K, V](),
+		mode: mode,
+	}
+}
+
+// Clear removes all elements from the map.
+func (s *SyncMap[K, V]) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m.Clear()
+}
+
+// Len returns the number of elements in the map.
+func (s *SyncMap[K, V]) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.Len()
+}
+
+// Get returns a copy of the value stored for key, and true if it
+// was found.
+func (s *SyncMap[K, V]) Get(key K) (V, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.Get(key)
+}
+
+// Contains returns true if the key is in the map.
+func (s *SyncMap[K, V]) Contains(key K) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.Contains(key)
+}
+
+// Set stores the value for the key, returning the value it replaced
+// along with true if the key was present.
+func (s *SyncMap[K, V]) Set(k K, v V) (V, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m.Set(k, v)
+}
+
+// GetOrAdd returns the value stored for the key if there is one, along
+// with true. Otherwise it stores v and returns it along with false.
+func (s *SyncMap[K, V]) GetOrAdd(k K, v V) (V, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, dupe := s.m.Add(k, v)
+	return *p, dupe
+}
+
+// CompareAndSwap stores new for the key, provided the value stored is
+// equal to old using ==. It reports whether the swap was made. It panics
+// if V is not comparable.
+func (s *SyncMap[K, V]) CompareAndSwap(k K, old, new V) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if v, ok := s.m.Get(k); !ok || any(v) != any(old) {
+		return false
+	}
+	s.m.Set(k, new)
+	return true
+}
+
+// Update sets the value stored for the key to the result of f, which is
+// called with the current value and true if the key is present, or the
+// zero value and false if it is not. The new value is returned.
+func (s *SyncMap[K, V]) Update(k K, f func(old V, exists bool) V) V {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m.Update(k, f)
+}
+
+// Remove removes the key, returning its value along with true if it
+// was present.
+func (s *SyncMap[K, V]) Remove(key K) (V, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return value(s.m.Remove(key))
+}
+
+// Snapshot returns an unsynchronized copy of the map, made in constant
+// time.
+func (s *SyncMap[K, V]) Snapshot() *Map[K, V] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m.Clone()
+}
+
+// walk calls f with the map to iterate, according to the iteration mode.
+func (s *SyncMap[K, V]) walk(f func(m *Map[K, V])) {
+	if s.mode == SnapshotIteration {
+		f(s.Snapshot())
+		return
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	f(&s.m)
+}
+
+// Do calls function f for each element of the map, in order.
+func (s *SyncMap[K, V]) Do(f func(K, V) bool) {
+	s.walk(func(m *Map[K, V]) {
+		m.Do(f)
+	})
+}
+
+// All returns an iterator over the keys and values of the map, in order.
+func (s *SyncMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		s.walk(func(m *Map[K, V]) {
+			m.Do(yield)
+		})
+	}
+}
+
+// Backward returns an iterator over the keys and values of the map,
+// in reverse order.
+func (s *SyncMap[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		s.walk(func(m *Map[K, V]) {
+			m.DoReverse(yield)
+		})
+	}
+}
+
+// Range returns an iterator over the keys and values of the map with keys
+// between lo and hi, in order. The bounds determine whether lo and hi
+// themselves are included.
+func (s *SyncMap[K, V]) Range(lo, hi K, bounds Bounds) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		s.walk(func(m *Map[K, V]) {
+			m.DoBetween(lo, hi, bounds, yield)
+		})
+	}
+}
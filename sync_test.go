@@ -0,0 +1,236 @@
+package avltree
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestSyncTree(t *testing.T) {
+	for _, mode := range []SyncMode{LockedIteration, SnapshotIteration} {
+		s := NewSyncTreeOrdered[int](0, mode)
+
+		var wg sync.WaitGroup
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for j := 0; j < 500; j++ {
+					s.Add(g*1000 + j)
+					s.Contains(j)
+					if j%50 == 0 {
+						prev := -1
+						for v := range s.All() {
+							if v <= prev {
+								t.Errorf("Iteration out of order: %d after %d\n", v, prev)
+							}
+							prev = v
+						}
+					}
+				}
+			}(g)
+		}
+		wg.Wait()
+
+		if s.Len() != 4000 {
+			t.Errorf("Mode %d: tree should have 4000 elements: %d\n", mode, s.Len())
+		}
+
+		if v, loaded := s.GetOrAdd(5); !loaded || v != 5 {
+			t.Errorf("GetOrAdd(5) should load 5: %v/%v\n", v, loaded)
+		}
+
+		if v, loaded := s.GetOrAdd(-5); loaded || v != -5 || s.Len() != 4001 {
+			t.Errorf("GetOrAdd(-5) should add -5: %v/%v/%d\n", v, loaded, s.Len())
+		}
+
+		if !s.CompareAndSwap(-5, -6) || s.Contains(-5) || !s.Contains(-6) {
+			t.Errorf("CompareAndSwap(-5, -6) should swap\n")
+		}
+
+		if s.CompareAndSwap(-5, -7) {
+			t.Errorf("CompareAndSwap(-5, -7) should fail\n")
+		}
+
+		if v, ok := s.Min(); !ok || v != -6 {
+			t.Errorf("Min should be -6: %v/%v\n", v, ok)
+		}
+
+		if v, ok := s.Remove(-6); !ok || v != -6 {
+			t.Errorf("Remove(-6) should return -6: %v/%v\n", v, ok)
+		}
+	}
+}
+
+func TestSyncTreeSnapshotIteration(t *testing.T) {
+	s := NewSyncTreeOrdered[int](0, SnapshotIteration)
+
+	for j := 0; j < 100; j++ {
+		s.Add(j)
+	}
+
+	// the tree can be changed while iterating a snapshot
+	n := 0
+	for v := range s.All() {
+		s.Remove(v)
+		s.Add(v + 1000)
+		n++
+	}
+
+	if n != 100 || s.Len() != 100 {
+		t.Errorf("Snapshot iteration should see 100 elements: %d/%d\n", n, s.Len())
+	}
+
+	if v, ok := s.Min(); !ok || v != 1000 {
+		t.Errorf("Min should be 1000: %v/%v\n", v, ok)
+	}
+
+	snap := s.Snapshot()
+	s.Clear()
+
+	if snap.Len() != 100 || s.Len() != 0 {
+		t.Errorf("Snapshot should keep 100 elements: %d/%d\n", snap.Len(), s.Len())
+	}
+}
+
+func TestSyncTreeCompareAndSwapDuplicates(t *testing.T) {
+	type entry struct{ key, id int }
+	s := NewSyncTree(func(a, b entry) int { return a.key - b.key }, AllowDuplicates|DuplicatesFIFO, LockedIteration)
+	s.Add(entry{1, 1})
+	s.Add(entry{1, 2})
+	s.Add(entry{1, 3})
+
+	if !s.CompareAndSwap(entry{1, 2}, entry{1, 4}) {
+		t.Errorf("CompareAndSwap of {1 2} should swap\n")
+	}
+	if s.CompareAndSwap(entry{1, 2}, entry{1, 5}) {
+		t.Errorf("CompareAndSwap of a missing {1 2} should not swap\n")
+	}
+
+	var ids []int
+	for e := range s.All() {
+		ids = append(ids, e.id)
+	}
+	if !slices.Equal(ids, []int{1, 4, 3}) {
+		t.Errorf("CompareAndSwap should replace only {1 2} in place: %v\n", ids)
+	}
+
+	// moving to another key joins the end of that key's group
+	s.Add(entry{2, 5})
+	if !s.CompareAndSwap(entry{1, 4}, entry{2, 6}) {
+		t.Errorf("CompareAndSwap of {1 4} to {2 6} should swap\n")
+	}
+	ids = ids[:0]
+	for e := range s.All() {
+		ids = append(ids, e.id)
+	}
+	if !slices.Equal(ids, []int{1, 3, 5, 6}) {
+		t.Errorf("CompareAndSwap should move {1 4} to key 2: %v\n", ids)
+	}
+}
+
+func TestSyncTreeCompareAndSwapCollision(t *testing.T) {
+	s := NewSyncTreeOrdered[int](0, LockedIteration)
+	s.Add(1)
+	s.Add(2)
+
+	if s.CompareAndSwap(1, 2) {
+		t.Errorf("CompareAndSwap(1, 2) should not swap onto an existing 2\n")
+	}
+	if s.Len() != 2 || !s.Contains(1) || !s.Contains(2) {
+		t.Errorf("Failed CompareAndSwap should leave the tree alone: %d\n", s.Len())
+	}
+	if !s.CompareAndSwap(1, 1) || s.Len() != 2 {
+		t.Errorf("CompareAndSwap(1, 1) should swap in place: %d\n", s.Len())
+	}
+
+	// replacing in place copies nodes shared with a snapshot
+	snap := s.Snapshot()
+	if !s.CompareAndSwap(2, 2) || snap.root == s.t.root {
+		t.Errorf("CompareAndSwap should copy shared nodes\n")
+	}
+	checkTree(t, &s.t)
+	checkTree(t, snap)
+}
+
+func TestSyncTreeUpdateKey(t *testing.T) {
+	s := NewSyncTreeOrdered[int](0, LockedIteration)
+	for i := 0; i < 10; i++ {
+		s.Add(i * 10)
+	}
+
+	for _, key := range []int{50, 55} {
+		if r := panics(func() {
+			s.Update(key, func(old int, exists bool) int { return 1000 })
+		}); r == nil {
+			t.Errorf("Update(%d) with a different key should panic\n", key)
+		}
+	}
+
+	if s.Len() != 10 || !s.Contains(50) || s.Contains(1000) {
+		t.Errorf("Failed Update should leave the tree alone: %d\n", s.Len())
+	}
+	checkTree(t, &s.t)
+
+	if v := s.Update(55, func(old int, exists bool) int { return old }); v != 55 || s.Len() != 11 {
+		t.Errorf("Update should still work after a failed one: %d\n", v)
+	}
+}
+
+func TestSyncMap(t *testing.T) {
+	for _, mode := range []SyncMode{LockedIteration, SnapshotIteration} {
+		s := NewSyncMapOrdered[int, int](mode)
+
+		var wg sync.WaitGroup
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 500; j++ {
+					s.Update(j%10, func(old int, exists bool) int {
+						return old + 1
+					})
+					if j%100 == 0 {
+						for range s.All() {
+						}
+					}
+				}
+			}()
+		}
+		wg.Wait()
+
+		total := 0
+		s.Do(func(k, v int) bool {
+			total += v
+			return true
+		})
+
+		if total != 4000 || s.Len() != 10 {
+			t.Errorf("Mode %d: Update should count 4000 in 10 keys: %d/%d\n", mode, total, s.Len())
+		}
+
+		if v, loaded := s.GetOrAdd(20, 1); loaded || v != 1 {
+			t.Errorf("GetOrAdd(20) should add 1: %v/%v\n", v, loaded)
+		}
+
+		if v, loaded := s.GetOrAdd(20, 2); !loaded || v != 1 {
+			t.Errorf("GetOrAdd(20) should load 1: %v/%v\n", v, loaded)
+		}
+
+		if !s.CompareAndSwap(20, 1, 3) || s.CompareAndSwap(20, 1, 4) || s.CompareAndSwap(21, 0, 4) {
+			t.Errorf("CompareAndSwap should only swap 1 for 3\n")
+		}
+
+		if v, ok := s.Get(20); !ok || v != 3 {
+			t.Errorf("Get(20) should be 3: %v/%v\n", v, ok)
+		}
+
+		if old, replaced := s.Set(20, 5); !replaced || old != 3 {
+			t.Errorf("Set(20) should replace 3: %v/%v\n", old, replaced)
+		}
+
+		if v, ok := s.Remove(20); !ok || v != 5 || s.Contains(20) {
+			t.Errorf("Remove(20) should return 5: %v/%v\n", v, ok)
+		}
+	}
+}
@@ -22,7 +22,9 @@ tree; however, the indexes are not stable as the tree could be
 resorted as items are added or removed.
 
 It is safe to iterate or search a tree from multiple threads
-provided that no threads are modifying the tree.
+provided that no threads are modifying the tree. SyncTree and
+SyncMap may be used when threads need to modify it as well, and
+AtomicTree when readers must never wait for writers.
 
 See also:	Robert L. Kruse, Data Structures and Program Design, 2nd Ed., Prentice-Hall
 */
@@ -31,14 +33,25 @@ package avltree
 import (
 	"cmp"
 	"context"
+	"errors"
+	"iter"
 	"math"
+	"sync"
 )
 
 // tree options
 const (
-	AllowDuplicates = 1
+	AllowDuplicates = 1 // equal elements may be added more than once
+	DuplicatesFIFO  = 2 // with AllowDuplicates, equal elements keep insertion order
 )
 
+// ErrConcurrentModification is the value with which iterators and cursors
+// panic when the tree is modified other than through them while they are
+// in use. Detection is best effort: modifications made by other threads
+// without synchronization are a data race and may go unnoticed. Channels
+// returned by Iter and its variants read a snapshot, so they are unaffected.
+var ErrConcurrentModification = errors.New("avltree: tree modified during iteration")
+
 // compareFunc defines the function type used to compare values.
 type compareFunc[T any] func(T, T) int
 
@@ -55,6 +68,12 @@ type Tree[T any] struct {
 
 	// options controlling behavior
 	treeFlags byte
+
+	// owner of the nodes this tree may change in place
+	owner *owner
+
+	// count of modifications, used to detect them during iteration
+	mods int
 }
 
 // New returns an initialized tree.
@@ -77,6 +96,41 @@ func NewOrdered[T cmp.Ordered](flags byte) *Tree[T] {
 // current options and compare function.
 func (t *Tree[T]) Clear() {
 	t.root = nil
+	t.mods++
+}
+
+// shareMu serializes the changes share makes to trees being read, so
+// that concurrent readers may call it.
+var shareMu sync.Mutex
+
+// share gives the tree a new owner, so that it copies the nodes it holds
+// now before changing them. It is used when those nodes become shared
+// with another tree, and like other reads it may be called by several
+// threads at once.
+func (t *Tree[T]) share() {
+	shareMu.Lock()
+	t.owner = new(owner)
+	shareMu.Unlock()
+}
+
+// Clone returns a copy of the tree in constant time. The two trees
+// share their nodes, and each copies the nodes it changes on its next
+// modification. Like other reads, Clone may be called by several threads
+// at once, provided that no threads are modifying the tree.
+//
+// Pointers returned by methods such as Find, At, Min, Max, Floor and Add
+// refer to nodes that may be shared, so once a tree is cloned they should
+// not be written through; doing so changes the clone as well. Elements
+// returned by PopMin, PopMax, Remove and RemoveAt belong to neither tree
+// and may be changed freely.
+func (t *Tree[T]) Clone() *Tree[T] {
+	t.share()
+	return &Tree[T]{
+		root:      t.root,
+		compare:   t.compare,
+		treeFlags: t.treeFlags,
+		owner:     new(owner),
+	}
 }
 
 // calcHeightData contains information needed to compute the
@@ -170,6 +224,38 @@ func (t *Tree[T]) At(index int) *T {
 	return nil
 }
 
+// minNode returns the leftmost node of the subtree.
+func minNode[T any](node *treeNode[T]) *treeNode[T] {
+	for node != nil && node.left != nil {
+		node = node.left
+	}
+	return node
+}
+
+// maxNode returns the rightmost node of the subtree.
+func maxNode[T any](node *treeNode[T]) *treeNode[T] {
+	for node != nil && node.right != nil {
+		node = node.right
+	}
+	return node
+}
+
+// Min returns the smallest element in the tree, or nil if the tree is empty.
+func (t *Tree[T]) Min() *T {
+	if node := minNode(t.root); node != nil {
+		return &node.value
+	}
+	return nil
+}
+
+// Max returns the largest element in the tree, or nil if the tree is empty.
+func (t *Tree[T]) Max() *T {
+	if node := maxNode(t.root); node != nil {
+		return &node.value
+	}
+	return nil
+}
+
 // findData[T] is used when searching the tree.
 type findData[T any] struct {
 	lookingFor T              // item we are searching for
@@ -206,6 +292,27 @@ func (t *Tree[T]) Find(key T) *T {
 	return nil
 }
 
+// value returns a copy of the element v points to, and false if v is nil.
+func value[T any](v *T) (T, bool) {
+	if v != nil {
+		return *v, true
+	}
+	var zero T
+	return zero, false
+}
+
+// Get returns a copy of the element matching the given key value, and
+// true if it was found. Unlike Find, no pointer into the tree is returned.
+func (t *Tree[T]) Get(key T) (T, bool) {
+	return value(t.Find(key))
+}
+
+// Contains returns true if an element matching the given key value
+// is in the tree.
+func (t *Tree[T]) Contains(key T) bool {
+	return t.Find(key) != nil
+}
+
 // iterate recursively traverses the tree and executes
 // the iteration function.
 func (d iterateFunc[T]) iterate(node *treeNode[T]) bool {
@@ -233,19 +340,73 @@ func (d iterateFunc[T]) iterate(node *treeNode[T]) bool {
 	return true
 }
 
+// iterateReverse recursively traverses the tree from the largest
+// element to the smallest and executes the iteration function.
+func (d iterateFunc[T]) iterateReverse(node *treeNode[T]) bool {
+	var proceed bool
+
+	if node.right != nil {
+		proceed = d.iterateReverse(node.right)
+		if !proceed {
+			return false
+		}
+	}
+
+	proceed = d(node.value)
+	if !proceed {
+		return false
+	}
+
+	if node.left != nil {
+		proceed = d.iterateReverse(node.left)
+		if !proceed {
+			return false
+		}
+	}
+
+	return true
+}
+
+// guard wraps f so that it panics with ErrConcurrentModification if the
+// tree was modified during the call and iteration is to continue.
+// Modifying the tree and then stopping is allowed.
+func (t *Tree[T]) guard(f func(T) bool) func(T) bool {
+	mods := t.mods
+	return func(v T) bool {
+		proceed := f(v)
+		if proceed && t.mods != mods {
+			panic(ErrConcurrentModification)
+		}
+		return proceed
+	}
+}
+
 // Do calls function f for each element of the tree, in order.
-// The function should not change the structure of the tree underfoot.
+// The function should not change the structure of the tree underfoot;
+// if it does and returns true, Do panics with ErrConcurrentModification.
 func (t *Tree[T]) Do(f func(T) bool) {
 
 	if f != nil && t.root != nil {
-		iterateFunc[T](f).iterate(t.root)
+		iterateFunc[T](t.guard(f)).iterate(t.root)
+	}
+}
+
+// DoReverse calls function f for each element of the tree, in reverse order.
+// The function should not change the structure of the tree underfoot;
+// if it does and returns true, DoReverse panics with ErrConcurrentModification.
+func (t *Tree[T]) DoReverse(f func(T) bool) {
+
+	if f != nil && t.root != nil {
+		iterateFunc[T](t.guard(f)).iterateReverse(t.root)
 	}
 }
 
 // chanIterate should be used as a goroutine to produce all the values
-// in the tree.
-func (t *Tree[T]) chanIterate(ctx context.Context, c chan<- T) {
-	t.Do(func(v T) bool {
+// in the tree, using do to walk the tree. The tree should be a clone that
+// no other goroutine uses, so that callers may change the original while
+// they read the channel.
+func (t *Tree[T]) chanIterate(ctx context.Context, c chan<- T, do func(func(T) bool)) {
+	do(func(v T) bool {
 		select {
 		case c <- v:
 			return true
@@ -257,19 +418,75 @@ func (t *Tree[T]) chanIterate(ctx context.Context, c chan<- T) {
 }
 
 // Iter returns a channel you can read through to fetch all the items.
+// The items are those in the tree when Iter is called, and the tree may
+// be changed while the channel is read.
 func (t *Tree[T]) Iter() <-chan T {
 	c := make(chan T)
-	go t.chanIterate(context.Background(), c)
+	s := t.Clone()
+	go s.chanIterate(context.Background(), c, s.Do)
 	return c
 }
 
 // IterContext returns a channel you can read through to fetch all the items.
 func (t *Tree[T]) IterContext(ctx context.Context) <-chan T {
 	c := make(chan T)
-	go t.chanIterate(ctx, c)
+	s := t.Clone()
+	go s.chanIterate(ctx, c, s.Do)
+	return c
+}
+
+// IterReverse returns a channel you can read through to fetch all the items
+// in reverse order.
+func (t *Tree[T]) IterReverse() <-chan T {
+	c := make(chan T)
+	s := t.Clone()
+	go s.chanIterate(context.Background(), c, s.DoReverse)
+	return c
+}
+
+// IterReverseContext returns a channel you can read through to fetch all
+// the items in reverse order.
+func (t *Tree[T]) IterReverseContext(ctx context.Context) <-chan T {
+	c := make(chan T)
+	s := t.Clone()
+	go s.chanIterate(ctx, c, s.DoReverse)
 	return c
 }
 
+// All returns an iterator over all the elements of the tree, in order.
+// Unlike Iter, no goroutine is used and the loop may be exited early.
+// The loop body should not change the structure of the tree underfoot
+// unless it then exits the loop; otherwise the iterator panics with
+// ErrConcurrentModification.
+func (t *Tree[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		t.Do(yield)
+	}
+}
+
+// Backward returns an iterator over all the elements of the tree,
+// in reverse order.
+func (t *Tree[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		t.DoReverse(yield)
+	}
+}
+
+// Indexed returns an iterator over the index and value of each
+// element of the tree, in order.
+func (t *Tree[T]) Indexed() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		i := 0
+		t.Do(func(v T) bool {
+			if !yield(i, v) {
+				return false
+			}
+			i++
+			return true
+		})
+	}
+}
+
 // Data returns all the elements as a slice.
 func (t *Tree[T]) Data() []T {
 	arr := make([]T, t.Len())
@@ -1,7 +1,10 @@
 package avltree
 
 import (
+	"errors"
 	"math/rand"
+	"slices"
+	"sync"
 	"testing"
 )
 
@@ -223,3 +226,608 @@ func TestTree(t *testing.T) {
 	})
 
 }
+
+func TestTreeSeq(t *testing.T) {
+	tree := NewOrdered[int](0)
+
+	for _, v := range []int{5, 3, 8, 1, 4, 7, 9, 2, 6} {
+		tree.Add(v)
+	}
+
+	x := 1
+	for v := range tree.All() {
+		if v != x {
+			t.Errorf("All expected %d, got %d\n", x, v)
+		}
+		x++
+	}
+
+	if x != 10 {
+		t.Errorf("All ran wrong number of elements, expected 9, got %d\n", x-1)
+	}
+
+	x = 9
+	for v := range tree.Backward() {
+		if v != x {
+			t.Errorf("Backward expected %d, got %d\n", x, v)
+		}
+		x--
+	}
+
+	if x != 0 {
+		t.Errorf("Backward ran wrong number of elements, expected 9, got %d\n", 9-x)
+	}
+
+	for i, v := range tree.Indexed() {
+		if v != i+1 {
+			t.Errorf("Indexed expected %d at %d, got %d\n", i+1, i, v)
+		}
+	}
+
+	// early exit
+
+	x = 0
+	for v := range tree.All() {
+		if v > 3 {
+			break
+		}
+		x++
+	}
+
+	if x != 3 {
+		t.Errorf("All should stop after 3 elements, got %d\n", x)
+	}
+
+	// empty tree
+
+	tree.Clear()
+	for v := range tree.All() {
+		t.Errorf("Empty tree should not yield values: %v\n", v)
+	}
+	for v := range tree.Backward() {
+		t.Errorf("Empty tree should not yield values: %v\n", v)
+	}
+}
+
+func TestTreeReverse(t *testing.T) {
+	tree := NewOrdered[int](0)
+
+	for j := 1; j <= 100; j++ {
+		tree.Add(j)
+	}
+
+	// test DoReverse
+
+	x := 100
+	tree.DoReverse(func(z int) bool {
+		if z != x {
+			t.Errorf("DoReverse expected %d, got %d\n", x, z)
+		}
+		x--
+		return x > 90
+	})
+
+	if x != 90 {
+		t.Errorf("DoReverse should stop after 10 elements, got %d\n", 100-x)
+	}
+
+	// test IterReverse
+
+	x = 100
+	for v := range tree.IterReverse() {
+		if v != x {
+			t.Errorf("IterReverse expected %d, got %d\n", x, v)
+		}
+		x--
+	}
+
+	if x != 0 {
+		t.Errorf("IterReverse ran wrong number of elements, expected 100, got %d\n", 100-x)
+	}
+}
+
+// checkNode verifies the balance factors and sizes of the subtree,
+// returning its height.
+func checkNode[T any](t *testing.T, node *treeNode[T]) int {
+	if node == nil {
+		return 0
+	}
+
+	lh := checkNode(t, node.left)
+	rh := checkNode(t, node.right)
+
+	var bal byte
+	switch {
+	case lh == rh:
+		bal = equal
+	case lh == rh+1:
+		bal = leftHigh
+	case rh == lh+1:
+		bal = rightHigh
+	default:
+		t.Errorf("Node %v is unbalanced: left height %d, right height %d\n", node.value, lh, rh)
+	}
+
+	if node.bal != bal {
+		t.Errorf("Node %v has balance factor %d, expected %d\n", node.value, node.bal, bal)
+	}
+
+	if node.size != node.leftSize()+node.rightSize() {
+		t.Errorf("Node %v has size %d, expected %d\n", node.value, node.size, node.leftSize()+node.rightSize())
+	}
+
+	return max(lh, rh) + 1
+}
+
+// checkTree verifies that the tree is a valid AVL tree in sorted order.
+func checkTree[T any](t *testing.T, tree *Tree[T]) {
+	t.Helper()
+
+	checkNode(t, tree.root)
+
+	var prev *T
+	tree.Do(func(v T) bool {
+		if prev != nil && tree.compare(*prev, v) > 0 {
+			t.Errorf("Elements not in order, previous = %v, current = %v\n", *prev, v)
+			return false
+		}
+		prev = &v
+		return true
+	})
+}
+
+func TestMinMax(t *testing.T) {
+	tree := NewOrdered[int](AllowDuplicates)
+
+	if tree.Min() != nil || tree.Max() != nil || tree.PopMin() != nil || tree.PopMax() != nil {
+		t.Errorf("Min and Max of empty tree should be nil\n")
+	}
+
+	for j := 0; j < 1000; j++ {
+		tree.Add(rand.Intn(500))
+	}
+
+	data := tree.Data()
+
+	if v := tree.Min(); v == nil || *v != data[0] {
+		t.Errorf("Min should be %d: %v\n", data[0], v)
+	}
+
+	if v := tree.Max(); v == nil || *v != data[len(data)-1] {
+		t.Errorf("Max should be %d: %v\n", data[len(data)-1], v)
+	}
+
+	// pop from both ends, like a double-ended priority queue
+
+	lo, hi := 0, len(data)-1
+	for tree.Len() > 0 {
+		if tree.Len()%2 == 0 {
+			v := tree.PopMin()
+			if v == nil || *v != data[lo] {
+				t.Fatalf("PopMin should be %d: %v\n", data[lo], v)
+			}
+			lo++
+		} else {
+			v := tree.PopMax()
+			if v == nil || *v != data[hi] {
+				t.Fatalf("PopMax should be %d: %v\n", data[hi], v)
+			}
+			hi--
+		}
+		if tree.Len() != hi-lo+1 {
+			t.Fatalf("Tree should have %d elements: %d\n", hi-lo+1, tree.Len())
+		}
+		if tree.Len()%100 == 0 {
+			checkTree(t, tree)
+		}
+	}
+
+	if tree.root != nil {
+		t.Errorf("Tree should be empty after popping everything: %v\n", tree.root)
+	}
+}
+
+func TestGet(t *testing.T) {
+	tree := NewOrdered[int](0)
+
+	if v, ok := tree.Get(1); ok || v != 0 {
+		t.Errorf("Get on empty tree should be 0/false: %v/%v\n", v, ok)
+	}
+
+	tree.Add(1)
+	tree.Add(2)
+
+	if v, ok := tree.Get(2); !ok || v != 2 {
+		t.Errorf("Get should locate 2: %v/%v\n", v, ok)
+	}
+
+	if !tree.Contains(1) || tree.Contains(3) {
+		t.Errorf("Contains should be true for 1 and false for 3\n")
+	}
+}
+
+func TestRemoveResult(t *testing.T) {
+	tree := NewOrdered[int](0)
+
+	for j := 0; j < 100; j++ {
+		tree.Add(j)
+	}
+
+	// removing inner nodes must return the removed value, not the
+	// predecessor moved into its place
+	for j := 0; j < 100; j += 2 {
+		if v := tree.Remove(j); v == nil || *v != j {
+			t.Errorf("Remove(%d) returned %v\n", j, v)
+		}
+	}
+
+	for tree.Len() > 0 {
+		i := tree.Len() / 2
+		want := *tree.At(i)
+		if v := tree.RemoveAt(i); v == nil || *v != want {
+			t.Errorf("RemoveAt(%d) should return %d: %v\n", i, want, v)
+		}
+	}
+}
+
+func TestRemoveIf(t *testing.T) {
+	tree := NewOrdered[int](AllowDuplicates)
+
+	for j := 0; j < 5000; j++ {
+		tree.Add(rand.Intn(1000))
+	}
+
+	odd := 0
+	tree.Do(func(v int) bool {
+		if v%2 == 1 {
+			odd++
+		}
+		return true
+	})
+
+	if n := tree.RemoveIf(func(v int) bool { return v%2 == 1 }); n != odd {
+		t.Errorf("RemoveIf should remove %d odd values: %d\n", odd, n)
+	}
+	checkTree(t, tree)
+
+	if tree.Len() != 5000-odd {
+		t.Errorf("RemoveIf should leave %d values: %d\n", 5000-odd, tree.Len())
+	}
+
+	if n := tree.RemoveIf(func(v int) bool { return v%2 == 1 }); n != 0 {
+		t.Errorf("RemoveIf again should remove nothing: %d\n", n)
+	}
+
+	big := tree.CountRange(500, 1000, HalfOpen)
+
+	if n := tree.Retain(func(v int) bool { return v < 500 }); n != big {
+		t.Errorf("Retain should remove %d values: %d\n", big, n)
+	}
+	checkTree(t, tree)
+
+	if v := tree.Max(); v == nil || *v >= 500 {
+		t.Errorf("Retain should leave values below 500: %v\n", v)
+	}
+}
+
+func TestRemoveRange(t *testing.T) {
+	for _, b := range []Bounds{Open, HalfOpen, IncludeHigh, Closed} {
+		tree := NewOrdered[int](0)
+		for j := 0; j < 1000; j++ {
+			tree.Add(j)
+		}
+
+		want := tree.CountRange(100, 200, b)
+		if n := tree.RemoveRange(100, 200, b); n != want {
+			t.Errorf("RemoveRange(100, 200, %d) should remove %d: %d\n", b, want, n)
+		}
+		checkTree(t, tree)
+
+		if tree.CountRange(100, 200, b) != 0 || tree.Len() != 1000-want {
+			t.Errorf("RemoveRange(100, 200, %d) left %d elements\n", b, tree.Len())
+		}
+	}
+
+	tree := NewOrdered[int](0)
+	for j := 0; j < 100; j++ {
+		tree.Add(j)
+	}
+
+	if n := tree.RemoveIndexRange(90, 200); n != 10 || tree.Len() != 90 {
+		t.Errorf("RemoveIndexRange(90, 200) should remove 10: %d/%d\n", n, tree.Len())
+	}
+
+	if n := tree.RemoveIndexRange(10, 20); n != 10 {
+		t.Errorf("RemoveIndexRange(10, 20) should remove 10: %d\n", n)
+	}
+	checkTree(t, tree)
+
+	if v := tree.At(10); v == nil || *v != 20 {
+		t.Errorf("RemoveIndexRange(10, 20) should move 20 to index 10: %v\n", v)
+	}
+
+	if n := tree.RemoveIndexRange(50, 40); n != 0 {
+		t.Errorf("RemoveIndexRange(50, 40) should remove nothing: %d\n", n)
+	}
+}
+
+func TestClone(t *testing.T) {
+	tree := NewOrdered[int](0)
+
+	for j := 0; j < 1000; j++ {
+		tree.Add(j)
+	}
+
+	clone := tree.Clone()
+
+	if clone.Len() != 1000 || clone.root != tree.root {
+		t.Errorf("Clone should share the root: %d\n", clone.Len())
+	}
+
+	// change both sides in every way and make sure neither sees the other
+
+	tree.Add(-1)
+	tree.Remove(10)
+	tree.RemoveAt(500)
+	tree.PopMax()
+	tree.ReplaceOrInsert(20)
+	clone.Add(2000)
+	clone.Remove(11)
+	clone.PopMin()
+	upper := clone.Split(900)
+	clone.RemoveRange(100, 200, HalfOpen)
+
+	checkTree(t, tree)
+	checkTree(t, clone)
+	checkTree(t, upper)
+
+	if tree.Len() != 998 || !tree.Contains(-1) || tree.Contains(10) || !tree.Contains(11) || tree.Contains(999) {
+		t.Errorf("Original tree has the wrong contents: %d\n", tree.Len())
+	}
+
+	if clone.Len() != 798 || clone.Contains(-1) || !clone.Contains(10) || clone.Contains(11) || clone.Contains(0) {
+		t.Errorf("Cloned tree has the wrong contents: %d\n", clone.Len())
+	}
+
+	if upper.Len() != 101 || !upper.Contains(999) || !upper.Contains(2000) {
+		t.Errorf("Split of the clone has the wrong contents: %d\n", upper.Len())
+	}
+
+	// clones of clones
+
+	odd := 0
+	tree.Do(func(v int) bool {
+		odd += v & 1
+		return true
+	})
+
+	a := tree.Clone()
+	b := a.Clone()
+	a.RemoveIf(func(v int) bool { return v%2 == 0 })
+	b.Add(5000)
+
+	if tree.Len() != 998 || a.Len() != odd || b.Len() != 999 {
+		t.Errorf("Clones of clones should be independent: %d/%d/%d\n", tree.Len(), a.Len(), b.Len())
+	}
+	checkTree(t, a)
+	checkTree(t, b)
+
+	// removed elements may be changed without affecting clones
+
+	c := a.Clone()
+	lo, hi := *c.Min(), *c.Max()
+	*a.PopMin() = 5000
+	*a.PopMax() = 5000
+	if *c.Min() != lo || *c.Max() != hi {
+		t.Errorf("Clone should not see changes to popped elements: %d, %d\n", *c.Min(), *c.Max())
+	}
+}
+
+func TestCloneConcurrent(t *testing.T) {
+	tree := NewOrdered[int](0)
+	for i := 0; i < 100; i++ {
+		tree.Add(i)
+	}
+
+	// clones are reads, so they may be taken from several goroutines
+	clones := make([]*Tree[int], 8)
+	var wg sync.WaitGroup
+	for i := range clones {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			clones[i] = tree.Clone()
+			clones[i].Add(100 + i)
+		}()
+	}
+	wg.Wait()
+
+	tree.Add(-1)
+	for i, c := range clones {
+		if c.Len() != 101 || !c.Contains(100+i) || c.Contains(-1) {
+			t.Errorf("Clone %d has the wrong contents: %d\n", i, c.Len())
+		}
+		checkTree(t, c)
+	}
+	checkTree(t, tree)
+}
+
+func TestCloneRandom(t *testing.T) {
+	trees := []*Tree[int]{NewOrdered[int](AllowDuplicates)}
+	models := [][]int{nil}
+
+	for j := 0; j < 3000; j++ {
+		i := rand.Intn(len(trees))
+		tree := trees[i]
+		v := rand.Intn(200)
+
+		switch rand.Intn(8) {
+		case 0:
+			if len(trees) < 8 {
+				trees = append(trees, tree.Clone())
+				models = append(models, slices.Clone(models[i]))
+			}
+		case 1:
+			// move everything into a fresh tree in two parts and back
+			upper := tree.Split(v)
+			fresh := NewOrdered[int](AllowDuplicates)
+			fresh.Join(tree)
+			fresh.Join(upper)
+			tree.Join(fresh)
+		case 2:
+			n := tree.RemoveRange(v, v+10, HalfOpen)
+			lo, _ := slices.BinarySearch(models[i], v)
+			hi, _ := slices.BinarySearch(models[i], v+10)
+			if n != hi-lo {
+				t.Fatalf("RemoveRange removed %d, expected %d\n", n, hi-lo)
+			}
+			models[i] = slices.Delete(models[i], lo, hi)
+		case 3:
+			if tree.Remove(v) != nil {
+				lo, _ := slices.BinarySearch(models[i], v)
+				models[i] = slices.Delete(models[i], lo, lo+1)
+			}
+		default:
+			tree.Add(v)
+			lo, _ := slices.BinarySearch(models[i], v)
+			models[i] = slices.Insert(models[i], lo, v)
+		}
+	}
+
+	for i, tree := range trees {
+		checkTree(t, tree)
+		if !slices.Equal(tree.Data(), models[i]) {
+			t.Errorf("Tree %d has the wrong contents\n", i)
+		}
+	}
+}
+
+// panics returns the value f panics with, or nil.
+func panics(f func()) (r any) {
+	defer func() {
+		r = recover()
+	}()
+	f()
+	return nil
+}
+
+func TestConcurrentModification(t *testing.T) {
+	tree := NewOrdered[int](0)
+
+	mods := map[string]func(){
+		"Add":      func() { tree.Add(1000) },
+		"Remove":   func() { tree.Remove(50) },
+		"RemoveAt": func() { tree.RemoveAt(0) },
+		"Clear":    func() { tree.Clear() },
+		"PopMax":   func() { tree.PopMax() },
+		"Split":    func() { tree.Split(90) },
+	}
+
+	for name, mod := range mods {
+		tree.Clear()
+		for i := 0; i < 100; i++ {
+			tree.Add(i)
+		}
+		r := panics(func() {
+			for v := range tree.All() {
+				if v == 10 {
+					mod()
+				}
+			}
+		})
+		if err, ok := r.(error); !ok || !errors.Is(err, ErrConcurrentModification) {
+			t.Errorf("%s during All should panic with ErrConcurrentModification: %v\n", name, r)
+		}
+	}
+
+	tree.Clear()
+	for i := 0; i < 100; i++ {
+		tree.Add(i)
+	}
+
+	if r := panics(func() {
+		tree.DoBetween(10, 20, Closed, func(v int) bool {
+			tree.Remove(v)
+			return true
+		})
+	}); r != ErrConcurrentModification {
+		t.Errorf("Remove during DoBetween should panic: %v\n", r)
+	}
+
+	if r := panics(func() {
+		for range tree.Backward() {
+			tree.Add(-1)
+		}
+	}); r != ErrConcurrentModification {
+		t.Errorf("Add during Backward should panic: %v\n", r)
+	}
+
+	// changes that leave the structure alone, or that end the loop, are allowed
+	if r := panics(func() {
+		for v := range tree.All() {
+			tree.Add(v) // duplicate, not added
+			tree.Remove(1000)
+			tree.ReplaceOrInsert(v)
+		}
+		for v := range tree.All() {
+			if v == 50 {
+				tree.Remove(v)
+				break
+			}
+		}
+	}); r != nil {
+		t.Errorf("Iteration should not panic: %v\n", r)
+	}
+
+	if tree.Contains(50) {
+		t.Errorf("50 should have been removed\n")
+	}
+}
+
+func TestIterModification(t *testing.T) {
+	tree := NewOrdered[int](0)
+	for i := 0; i < 100; i++ {
+		tree.Add(i)
+	}
+
+	// the channel produces a snapshot, so the tree may change underneath
+	n := 0
+	for v := range tree.Iter() {
+		tree.Remove(v)
+		tree.Add(v + 1000)
+		n++
+	}
+	if n != 100 || tree.Len() != 100 || tree.Contains(0) {
+		t.Errorf("Iter should produce the original 100 elements: %d, %d\n", n, tree.Len())
+	}
+
+	n = 0
+	for range tree.IterReverse() {
+		tree.Clear()
+		n++
+	}
+	if n != 100 || tree.Len() != 0 {
+		t.Errorf("IterReverse should produce 100 elements: %d\n", n)
+	}
+}
+
+func TestRemoveRangeOwner(t *testing.T) {
+	tree := NewOrdered[int](0)
+	for i := 0; i < 2000; i += 2 {
+		tree.Add(i)
+	}
+
+	// removing a range keeps the nodes owned, so later changes are in place
+	tree.RemoveRange(500, 600, HalfOpen)
+	checkTree(t, tree)
+
+	v := 1
+	allocs := testing.AllocsPerRun(200, func() {
+		tree.Add(v)
+		tree.Remove(v)
+		v += 8
+	})
+	if allocs > 1 {
+		t.Errorf("Add after RemoveRange should only allocate the new node: %v\n", allocs)
+	}
+}
@@ -2,9 +2,11 @@ package avltree
 
 // addData holds information used when adding nodes
 type addData[T any] struct {
-	lookingFor T        // Item to add
-	duplicate  *T       // Duplicate found, if any
-	tree       *Tree[T] // tree to add to
+	lookingFor T               // Item to add
+	duplicate  *T              // Duplicate found, if any
+	tree       *Tree[T]        // tree to add to
+	update     func(T, bool) T // computes the stored value, if set
+	copied     bool            // whether shared nodes were copied
 }
 
 func (d *addData[T]) add(node **treeNode[T], taller *bool) *T {
@@ -12,7 +14,11 @@ func (d *addData[T]) add(node **treeNode[T], taller *bool) *T {
 	var ptr *T
 
 	if *node == nil {
-		*node = &treeNode[T]{value: d.lookingFor}
+		if d.update != nil {
+			*node = &treeNode[T]{value: d.update(d.lookingFor, false), owner: d.tree.owner}
+		} else {
+			*node = &treeNode[T]{value: d.lookingFor, owner: d.tree.owner}
+		}
 		*taller = true
 		if *node != nil {
 			ptr = &(*node).value
@@ -20,10 +26,18 @@ func (d *addData[T]) add(node **treeNode[T], taller *bool) *T {
 	} else {
 		tallerSubTree := false
 
+		if n := (*node).mutable(d.tree.owner); n != *node {
+			*node = n
+			d.copied = true
+		}
 		code := d.tree.compare(d.lookingFor, (*node).value)
 
-		if code == 0 && (d.tree.treeFlags&AllowDuplicates) != 0 {
-			code = -1 // go left for duplicates
+		if code == 0 && (d.tree.treeFlags&AllowDuplicates) != 0 && d.update == nil {
+			if (d.tree.treeFlags & DuplicatesFIFO) != 0 {
+				code = 1 // go right to keep insertion order
+			} else {
+				code = -1 // go left for duplicates
+			}
 		}
 
 		if code < 0 {
@@ -32,7 +46,7 @@ func (d *addData[T]) add(node **treeNode[T], taller *bool) *T {
 				if tallerSubTree {
 					switch (*node).bal {
 					case leftHigh:
-						*node, *taller = leftBalance(*node, *taller)
+						*node, *taller = leftBalance(*node, *taller, d.tree.owner)
 					case equal:
 						(*node).bal = leftHigh
 						*taller = true
@@ -54,11 +68,14 @@ func (d *addData[T]) add(node **treeNode[T], taller *bool) *T {
 						(*node).bal = rightHigh
 						*taller = true
 					case rightHigh:
-						*node, *taller = rightBalance(*node, *taller)
+						*node, *taller = rightBalance(*node, *taller, d.tree.owner)
 					}
 				}
 			}
 		} else {
+			if d.update != nil {
+				(*node).value = d.update((*node).value, true)
+			}
 			d.duplicate = &(*node).value // this node is the duplicate
 		}
 
@@ -73,24 +90,88 @@ func (d *addData[T]) add(node **treeNode[T], taller *bool) *T {
 // Add adds an item to the tree, returning a pair indicating the added
 // (or duplicate) item, and a flag indicating whether the item is the
 // duplicate that was found. A duplicate will never be returned if the
-// tree's AllowDuplicates flag is set.
+// tree's AllowDuplicates flag is set. Duplicates are placed before the
+// equal items already in the tree, or after them if the DuplicatesFIFO
+// flag is also set.
 func (t *Tree[T]) Add(o T) (val *T, isDupe bool) {
-	d := &addData[T]{o, nil, t}
+	d := &addData[T]{o, nil, t, nil, false}
 	taller := false
 	isDupe = false
 	val = d.add(&t.root, &taller)
 	if val == nil {
 		isDupe = true
 		val = d.duplicate
+	} else {
+		t.mods++
+	}
+	return
+}
+
+// upsert adds an item to the tree in a single descent, using f to compute
+// the value stored. If an equal item is found, f is called with it and
+// true, and the result replaces it, even if the tree allows duplicates.
+// Otherwise f is called with o and false, and the result is added. A
+// pointer to the stored value is returned. Replacing an item counts as a
+// modification only if shared nodes had to be copied, since iterators
+// holding the old nodes would not see the new value.
+func (t *Tree[T]) upsert(o T, f func(T, bool) T) *T {
+	d := &addData[T]{o, nil, t, f, false}
+	taller := false
+	val := d.add(&t.root, &taller)
+	if val == nil {
+		val = d.duplicate
+		if d.copied {
+			t.mods++
+		}
+	} else {
+		t.mods++
+	}
+	return val
+}
+
+// replaceAt replaces the element at the given index with o, which must
+// compare equal to it. Nodes on the path that are shared are copied first,
+// which counts as a modification since iterators holding the old nodes
+// would no longer see the tree.
+func (t *Tree[T]) replaceAt(index int, o T) {
+	node := &t.root
+	for {
+		if n := (*node).mutable(t.owner); n != *node {
+			*node = n
+			t.mods++
+		}
+		ls := (*node).leftSize()
+		if index < ls {
+			node = &(*node).left
+		} else if index == ls {
+			(*node).value = o
+			return
+		} else {
+			index -= ls + 1
+			node = &(*node).right
+		}
 	}
+}
+
+// ReplaceOrInsert adds an item to the tree, replacing an existing item that
+// compares equal to it. If an item was replaced, it is returned along with
+// true. If the tree allows duplicates, one of the equal items is replaced.
+func (t *Tree[T]) ReplaceOrInsert(o T) (old T, replaced bool) {
+	t.upsert(o, func(v T, exists bool) T {
+		if exists {
+			old, replaced = v, true
+		}
+		return o
+	})
 	return
 }
 
-func rightBalance[T any](node *treeNode[T], taller bool) (*treeNode[T], bool) {
+func rightBalance[T any](node *treeNode[T], taller bool, o *owner) (*treeNode[T], bool) {
 	var x *treeNode[T] // right subtree of node
 	var w *treeNode[T] // left subtree of x
 
-	x = node.right
+	x = node.right.mutable(o)
+	node.right = x
 
 	switch x.bal {
 	case rightHigh:
@@ -101,7 +182,8 @@ func rightBalance[T any](node *treeNode[T], taller bool) (*treeNode[T], bool) {
 	case equal:
 		// this should be impossible
 	case leftHigh:
-		w = x.left
+		w = x.left.mutable(o)
+		x.left = w
 		switch w.bal {
 		case equal:
 			node.bal = equal
@@ -122,11 +204,12 @@ func rightBalance[T any](node *treeNode[T], taller bool) (*treeNode[T], bool) {
 	return node, taller
 }
 
-func leftBalance[T any](node *treeNode[T], taller bool) (*treeNode[T], bool) {
+func leftBalance[T any](node *treeNode[T], taller bool, o *owner) (*treeNode[T], bool) {
 	var x *treeNode[T] // left subtree of node
 	var w *treeNode[T] // right subtree of x
 
-	x = node.left
+	x = node.left.mutable(o)
+	node.left = x
 
 	switch x.bal {
 	case leftHigh:
@@ -137,7 +220,8 @@ func leftBalance[T any](node *treeNode[T], taller bool) (*treeNode[T], bool) {
 	case equal:
 		// this should be impossible
 	case rightHigh:
-		w = x.right
+		w = x.right.mutable(o)
+		x.right = w
 		switch w.bal {
 		case equal:
 			node.bal = equal
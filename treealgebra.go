@@ -0,0 +1,204 @@
+package avltree
+
+// mergeData describes how the elements of two sorted slices are
+// combined by a set operation.
+type mergeData[T any] struct {
+	compare  compareFunc[T] // Comparison function
+	keepA    bool           // keep elements found only in the first slice
+	keepB    bool           // keep elements found only in the second slice
+	keepBoth bool           // keep elements found in both slices
+	resolve  func(T, T) T   // chooses the element kept when both match
+}
+
+// merge combines the sorted slices a and b, matching equal elements
+// one to one.
+func (d *mergeData[T]) merge(a, b []T) []T {
+	arr := make([]T, 0, len(a)+len(b))
+	i, j := 0, 0
+
+	for i < len(a) && j < len(b) {
+		code := d.compare(a[i], b[j])
+		if code < 0 {
+			if d.keepA {
+				arr = append(arr, a[i])
+			}
+			i++
+		} else if code > 0 {
+			if d.keepB {
+				arr = append(arr, b[j])
+			}
+			j++
+		} else {
+			if d.keepBoth {
+				if d.resolve != nil {
+					arr = append(arr, d.resolve(a[i], b[j]))
+				} else {
+					arr = append(arr, a[i])
+				}
+			}
+			i++
+			j++
+		}
+	}
+
+	if d.keepA {
+		arr = append(arr, a[i:]...)
+	}
+	if d.keepB {
+		arr = append(arr, b[j:]...)
+	}
+
+	return arr
+}
+
+// setData describes how the elements of two trees without duplicates
+// are combined by a set operation using split and join.
+type setData[T any] struct {
+	*mergeData[T]
+	t     *Tree[T] // tree providing the comparison for splits
+	owner *owner   // owner of the result
+}
+
+// split3 divides the subtree of height h into the elements less than key
+// and those greater than key, returning the roots and heights of both
+// parts along with the element equal to key, or nil if there is none.
+func (d *setData[T]) split3(node *treeNode[T], h int, key T) (*treeNode[T], int, *treeNode[T], *treeNode[T], int) {
+	l, lh, r, rh := d.t.split(node, h, key, d.owner, d.owner)
+	if r == nil {
+		return l, lh, nil, r, rh
+	}
+	eq := minNode(r)
+	if d.compare(eq.value, key) != 0 {
+		return l, lh, nil, r, rh
+	}
+	r, _ = removeSuccessor(r, true, d.owner)
+	return l, lh, eq, r, height(r)
+}
+
+// combine returns the root and height of a tree holding the result of
+// combining the subtrees a and b, of heights ah and bh. Unchanged parts
+// of a and b are reused, and changed nodes are made to belong to the
+// owner of the result.
+func (d *setData[T]) combine(a *treeNode[T], ah int, b *treeNode[T], bh int) (*treeNode[T], int) {
+	if a == nil {
+		if d.keepB {
+			return b, bh
+		}
+		return nil, 0
+	}
+	if b == nil {
+		if d.keepA {
+			return a, ah
+		}
+		return nil, 0
+	}
+
+	alh, arh := childHeights(a, ah)
+	bl, blh, eq, br, brh := d.split3(b, bh, a.value)
+	l, lh := d.combine(a.left, alh, bl, blh)
+	r, rh := d.combine(a.right, arh, br, brh)
+
+	if eq != nil && d.keepBoth {
+		k := a.mutable(d.owner)
+		if d.resolve != nil {
+			k.value = d.resolve(a.value, eq.value)
+		}
+		return join(l, lh, k, r, rh, d.owner)
+	}
+	if eq == nil && d.keepA {
+		return join(l, lh, a, r, rh, d.owner)
+	}
+	return join2(l, lh, r, rh, d.owner)
+}
+
+// combine sets the root of u, which may be t itself, to a tree holding
+// the result of combining the elements of t and other. If t allows
+// duplicates, equal elements are matched one to one by merging in
+// O(n+m) time. Otherwise the trees are combined using split and join,
+// sharing their unchanged nodes with the result, in O(m log(n/m+1)) time
+// for trees of sizes m <= n; duplicates in other are collapsed first.
+func (t *Tree[T]) combine(other, u *Tree[T], md *mergeData[T]) {
+	md.compare = t.compare
+	if (t.treeFlags & AllowDuplicates) != 0 {
+		u.root, _ = build(md.merge(t.Data(), other.Data()), u.owner)
+		u.mods++
+		return
+	}
+
+	a, b := t.root, other.root
+	if (other.treeFlags & AllowDuplicates) != 0 {
+		b, _ = build(unique(other.Data(), t.compare), nil)
+	} else {
+		other.share()
+	}
+	t.share()
+
+	u.owner = new(owner)
+	d := &setData[T]{md, t, u.owner}
+	u.root, _ = d.combine(a, height(a), b, height(b))
+	u.mods++
+}
+
+// Union returns a new tree holding the elements found in either t or
+// other, which must use the same ordering. When an element of t equals
+// one in other, resolve is called with both to choose the element kept;
+// if resolve is nil, the element from t is kept. The new tree has the
+// options and compare function of t.
+//
+// Unless t allows duplicates, equal elements of other count once, and the
+// new tree shares its unchanged nodes with t and other as a clone would.
+// This takes O(m log(n/m+1)) time for trees of sizes m <= n. If t allows
+// duplicates, equal elements are matched one to one in O(n+m) time.
+func (t *Tree[T]) Union(other *Tree[T], resolve func(a, b T) T) *Tree[T] {
+	u := New(t.compare, t.treeFlags)
+	t.combine(other, u, &mergeData[T]{keepA: true, keepB: true, keepBoth: true, resolve: resolve})
+	return u
+}
+
+// Intersection returns a new tree holding the elements found in both t
+// and other, which must use the same ordering. The element kept is chosen
+// by resolve, and duplicates and time are as with Union.
+func (t *Tree[T]) Intersection(other *Tree[T], resolve func(a, b T) T) *Tree[T] {
+	u := New(t.compare, t.treeFlags)
+	t.combine(other, u, &mergeData[T]{keepBoth: true, resolve: resolve})
+	return u
+}
+
+// Difference returns a new tree holding the elements of t that are not
+// found in other, which must use the same ordering.
+func (t *Tree[T]) Difference(other *Tree[T]) *Tree[T] {
+	u := New(t.compare, t.treeFlags)
+	t.combine(other, u, &mergeData[T]{keepA: true})
+	return u
+}
+
+// SymmetricDifference returns a new tree holding the elements found in
+// exactly one of t and other, which must use the same ordering.
+func (t *Tree[T]) SymmetricDifference(other *Tree[T]) *Tree[T] {
+	u := New(t.compare, t.treeFlags)
+	t.combine(other, u, &mergeData[T]{keepA: true, keepB: true})
+	return u
+}
+
+// UnionWith adds the elements of other to t, as with Union.
+func (t *Tree[T]) UnionWith(other *Tree[T], resolve func(a, b T) T) {
+	t.combine(other, t, &mergeData[T]{keepA: true, keepB: true, keepBoth: true, resolve: resolve})
+}
+
+// IntersectWith removes the elements of t that are not found in other,
+// as with Intersection.
+func (t *Tree[T]) IntersectWith(other *Tree[T], resolve func(a, b T) T) {
+	t.combine(other, t, &mergeData[T]{keepBoth: true, resolve: resolve})
+}
+
+// DifferenceWith removes the elements of t that are found in other,
+// as with Difference.
+func (t *Tree[T]) DifferenceWith(other *Tree[T]) {
+	t.combine(other, t, &mergeData[T]{keepA: true})
+}
+
+// SymmetricDifferenceWith leaves t holding the elements found in exactly
+// one of t and other, as with SymmetricDifference.
+func (t *Tree[T]) SymmetricDifferenceWith(other *Tree[T]) {
+	t.combine(other, t, &mergeData[T]{keepA: true, keepB: true})
+}
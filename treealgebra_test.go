@@ -0,0 +1,227 @@
+package avltree
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestSetOperations(t *testing.T) {
+	a := NewOrdered[int](0)
+	b := NewOrdered[int](0)
+	inA := make(map[int]bool)
+	inB := make(map[int]bool)
+
+	for j := 0; j < 2000; j++ {
+		v := rand.Intn(3000)
+		a.Add(v)
+		inA[v] = true
+		v = rand.Intn(3000)
+		b.Add(v)
+		inB[v] = true
+	}
+
+	tests := []struct {
+		name   string
+		result *Tree[int]
+		want   func(v int) bool
+	}{
+		{"Union", a.Union(b, nil), func(v int) bool { return inA[v] || inB[v] }},
+		{"Intersection", a.Intersection(b, nil), func(v int) bool { return inA[v] && inB[v] }},
+		{"Difference", a.Difference(b), func(v int) bool { return inA[v] && !inB[v] }},
+		{"SymmetricDifference", a.SymmetricDifference(b), func(v int) bool { return inA[v] != inB[v] }},
+	}
+
+	for _, tc := range tests {
+		checkTree(t, tc.result)
+
+		n := 0
+		for v := 0; v < 3000; v++ {
+			if tc.want(v) {
+				n++
+				if !tc.result.Contains(v) {
+					t.Errorf("%s should contain %d\n", tc.name, v)
+				}
+			}
+		}
+
+		if tc.result.Len() != n {
+			t.Errorf("%s should have %d elements: %d\n", tc.name, n, tc.result.Len())
+		}
+	}
+
+	if a.Len() != len(inA) || b.Len() != len(inB) {
+		t.Errorf("Set operations should not change their inputs: %d/%d\n", a.Len(), b.Len())
+	}
+
+	// in place variants match
+
+	u := a.Union(b, nil)
+	a.UnionWith(b, nil)
+	checkTree(t, a)
+
+	if a.Len() != u.Len() {
+		t.Errorf("UnionWith should have %d elements: %d\n", u.Len(), a.Len())
+	}
+
+	a.DifferenceWith(b)
+	checkTree(t, a)
+
+	for v := range b.All() {
+		if a.Contains(v) {
+			t.Errorf("DifferenceWith should remove %d\n", v)
+		}
+	}
+
+	a.SymmetricDifferenceWith(b)
+	a.IntersectWith(b, nil)
+	checkTree(t, a)
+
+	if a.Len() != b.Len() {
+		t.Errorf("IntersectWith should leave %d elements: %d\n", b.Len(), a.Len())
+	}
+}
+
+func TestSetOperationsResolve(t *testing.T) {
+	byKey := func(a, b MyObject) int {
+		if a.Key < b.Key {
+			return -1
+		}
+		if a.Key > b.Key {
+			return 1
+		}
+		return 0
+	}
+
+	a := New[MyObject](byKey, 0)
+	b := New[MyObject](byKey, 0)
+	a.Add(MyObject{"x", "a"})
+	a.Add(MyObject{"y", "a"})
+	b.Add(MyObject{"y", "b"})
+	b.Add(MyObject{"z", "b"})
+
+	u := a.Union(b, func(x, y MyObject) MyObject {
+		return MyObject{x.Key, x.Value + y.Value}
+	})
+
+	if v, ok := u.Get(MyObject{Key: "y"}); !ok || v.Value != "ab" {
+		t.Errorf("Union should resolve y to ab: %v/%v\n", v, ok)
+	}
+
+	i := a.Intersection(b, nil)
+
+	if v, ok := i.Get(MyObject{Key: "y"}); !ok || v.Value != "a" || i.Len() != 1 {
+		t.Errorf("Intersection should keep y from the first tree: %v/%v/%d\n", v, ok, i.Len())
+	}
+}
+
+func TestMapSetOperations(t *testing.T) {
+	a := NewMapOrdered[string, int]()
+	b := NewMapOrdered[string, int]()
+	a.Add("x", 1)
+	a.Add("y", 2)
+	b.Add("y", 10)
+	b.Add("z", 20)
+
+	u := a.Union(b, func(k string, x, y int) int { return x + y })
+
+	if k := u.Keys(); len(k) != 3 {
+		t.Errorf("Union should have keys x,y,z: %v\n", k)
+	}
+
+	if v, _ := u.Get("y"); v != 12 {
+		t.Errorf("Union should merge y to 12: %d\n", v)
+	}
+
+	if v, _ := a.Intersection(b, nil).Get("y"); v != 2 {
+		t.Errorf("Intersection should keep y from the first map: %d\n", v)
+	}
+
+	if k := a.Difference(b).Keys(); len(k) != 1 || k[0] != "x" {
+		t.Errorf("Difference should have key x: %v\n", k)
+	}
+
+	a.SymmetricDifferenceWith(b)
+
+	if k := a.Keys(); len(k) != 2 || k[0] != "x" || k[1] != "z" {
+		t.Errorf("SymmetricDifferenceWith should have keys x,z: %v\n", k)
+	}
+}
+
+func TestSetOperationsFlags(t *testing.T) {
+	a := NewOrdered[int](0)
+	b := NewOrdered[int](AllowDuplicates)
+	a.Add(1)
+	a.Add(2)
+	b.Add(2)
+	b.Add(3)
+	b.Add(3)
+
+	if u := a.Union(b, nil); !slices.Equal(u.Data(), []int{1, 2, 3}) {
+		t.Errorf("Union into a tree without duplicates should collapse them: %v\n", u.Data())
+	}
+	if u := a.SymmetricDifference(b); !slices.Equal(u.Data(), []int{1, 3}) {
+		t.Errorf("SymmetricDifference should collapse duplicates: %v\n", u.Data())
+	}
+	if u := b.Union(a, nil); !slices.Equal(u.Data(), []int{1, 2, 3, 3}) {
+		t.Errorf("Union into a tree with duplicates should match one to one: %v\n", u.Data())
+	}
+	if b.Len() != 3 {
+		t.Errorf("Union should not change its inputs: %v\n", b.Data())
+	}
+}
+
+func TestSetOperationsSharing(t *testing.T) {
+	a := NewOrdered[int](0)
+	b := NewOrdered[int](0)
+	for i := 0; i < 1000; i++ {
+		a.Add(i)
+	}
+	for i := 500; i < 600; i++ {
+		b.Add(i * 2)
+	}
+
+	u := a.Union(b, nil)
+	if n := countShared(u.root, a.root); n < 500 {
+		t.Errorf("Union should share most nodes with a: %d\n", n)
+	}
+
+	// changes to any of the three trees leave the others alone
+	a.RemoveRange(0, 1000, HalfOpen)
+	b.Clear()
+	for i := 0; i < 1100; i += 2 {
+		u.Remove(i)
+	}
+	checkTree(t, u)
+	if u.Len() != 550 || a.Len() != 0 {
+		t.Errorf("Union should be independent of its inputs: %d/%d\n", u.Len(), a.Len())
+	}
+
+	a = NewOrdered[int](0)
+	for i := 0; i < 1000; i++ {
+		a.Add(i)
+	}
+	b = a.Clone()
+	b.RemoveIf(func(v int) bool { return v%3 != 0 })
+	c := b.Clone()
+
+	a.DifferenceWith(b)
+	b.Add(1)
+	b.PopMax()
+	checkTree(t, a)
+	checkTree(t, b)
+	if a.Len() != 666 || a.Contains(999) || !a.Contains(1) || c.Len() != 334 || b.Len() != 334 {
+		t.Errorf("DifferenceWith should be independent of its inputs: %d/%d/%d\n", a.Len(), b.Len(), c.Len())
+	}
+
+	a.IntersectWith(c, nil)
+	if a.Len() != 0 {
+		t.Errorf("IntersectWith should leave nothing: %v\n", a.Data())
+	}
+	a.UnionWith(c, nil)
+	c.Clear()
+	if a.Len() != 334 {
+		t.Errorf("UnionWith should be independent of its input: %d\n", a.Len())
+	}
+	checkTree(t, a)
+}
@@ -0,0 +1,102 @@
+package avltree
+
+import "slices"
+
+// build recursively constructs a perfectly balanced subtree from the
+// sorted values, returning its root and height. The nodes belong to o.
+func build[T any](values []T, o *owner) (*treeNode[T], int) {
+	if len(values) == 0 {
+		return nil, 0
+	}
+
+	mid := len(values) / 2
+	node := &treeNode[T]{value: values[mid], size: len(values) - 1, owner: o}
+
+	var lh, rh int
+	node.left, lh = build(values[:mid], o)
+	node.right, rh = build(values[mid+1:], o)
+
+	// the left half is never smaller than the right half
+	if lh > rh {
+		node.bal = leftHigh
+	}
+
+	return node, lh + 1
+}
+
+// unique removes adjacent equal values from the sorted values,
+// keeping the first of each.
+func unique[T any](values []T, c func(T, T) int) []T {
+	if len(values) < 2 {
+		return values
+	}
+	arr := make([]T, 1, len(values))
+	arr[0] = values[0]
+	for _, v := range values[1:] {
+		if c(arr[len(arr)-1], v) != 0 {
+			arr = append(arr, v)
+		}
+	}
+	return arr
+}
+
+// FromSorted returns a tree holding the given values, which must already
+// be sorted according to c. The tree is built directly in linear time.
+// Unless the AllowDuplicates flag is set, only the first of any equal
+// values is kept; otherwise equal values are stored in the order given.
+// The slice is not retained.
+func FromSorted[T any](values []T, c func(T, T) int, flags byte) *Tree[T] {
+	t := New(c, flags)
+	if (flags & AllowDuplicates) == 0 {
+		values = unique(values, c)
+	}
+	t.root, _ = build(values, t.owner)
+	return t
+}
+
+// FromUnsorted returns a tree holding the given values, sorting a copy of
+// them first. Unless the AllowDuplicates flag is set, only the first of any
+// equal values is kept. Otherwise equal values are ordered as if they had
+// been added one at a time: in their relative order if the DuplicatesFIFO
+// flag is set, and in reverse order if it is not.
+func FromUnsorted[T any](values []T, c func(T, T) int, flags byte) *Tree[T] {
+	arr := slices.Clone(values)
+	slices.SortStableFunc(arr, c)
+	if (flags&AllowDuplicates) != 0 && (flags&DuplicatesFIFO) == 0 {
+		reverseRuns(arr, c)
+	}
+	return FromSorted(arr, c, flags)
+}
+
+// reverseRuns reverses each run of equal values in the sorted slice, so
+// that the most recently given of them comes first.
+func reverseRuns[T any](values []T, c func(T, T) int) {
+	for i := 0; i < len(values); {
+		j := i + 1
+		for j < len(values) && c(values[i], values[j]) == 0 {
+			j++
+		}
+		slices.Reverse(values[i:j])
+		i = j
+	}
+}
+
+// MapFromSorted returns a map holding the given elements, which must already
+// be sorted by key according to c. The map is built directly in linear time.
+// Only the first of any elements with equal keys is kept.
+func MapFromSorted[K, V any](pairs []Pair[K, V], c func(K, K) int) *Map[K, V] {
+	m := NewMap[K, V](c)
+	m.t.root, _ = build(unique(pairs, m.t.compare), m.t.owner)
+	return m
+}
+
+// MapFromUnsorted returns a map holding the given elements, sorting a copy
+// of them by key first. Only the first of any elements with equal keys
+// is kept.
+func MapFromUnsorted[K, V any](pairs []Pair[K, V], c func(K, K) int) *Map[K, V] {
+	m := NewMap[K, V](c)
+	arr := slices.Clone(pairs)
+	slices.SortStableFunc(arr, m.t.compare)
+	m.t.root, _ = build(unique(arr, m.t.compare), m.t.owner)
+	return m
+}
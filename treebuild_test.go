@@ -0,0 +1,129 @@
+package avltree
+
+import (
+	"cmp"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestFromSorted(t *testing.T) {
+	for n := 0; n < 300; n++ {
+		values := make([]int, n)
+		for j := range values {
+			values[j] = j
+		}
+
+		tree := FromSorted(values, cmp.Compare[int], 0)
+		checkTree(t, tree)
+
+		if tree.Len() != n {
+			t.Fatalf("FromSorted should have %d elements: %d\n", n, tree.Len())
+		}
+
+		for j := 0; j < n; j++ {
+			if v := tree.At(j); v == nil || *v != j {
+				t.Fatalf("FromSorted element %d should be %d: %v\n", j, j, v)
+			}
+		}
+
+		// the tree must still work normally afterward
+		tree.Add(-1)
+		tree.Add(n)
+		tree.Remove(n / 2)
+		checkTree(t, tree)
+	}
+
+	// duplicates are dropped unless allowed
+
+	values := []int{1, 1, 2, 3, 3, 3}
+
+	if n := FromSorted(values, cmp.Compare[int], 0).Len(); n != 3 {
+		t.Errorf("FromSorted should drop duplicates, expected 3, got %d\n", n)
+	}
+
+	if n := FromSorted(values, cmp.Compare[int], AllowDuplicates).Len(); n != 6 {
+		t.Errorf("FromSorted should keep duplicates, expected 6, got %d\n", n)
+	}
+}
+
+func TestFromUnsorted(t *testing.T) {
+	values := make([]int, 10000)
+	for j := range values {
+		values[j] = rand.Intn(5000)
+	}
+	first := values[0]
+
+	tree := FromUnsorted(values, cmp.Compare[int], AllowDuplicates)
+	checkTree(t, tree)
+
+	if tree.Len() != len(values) {
+		t.Errorf("FromUnsorted should have %d elements: %d\n", len(values), tree.Len())
+	}
+
+	if values[0] != first {
+		t.Errorf("FromUnsorted should not sort the caller's slice\n")
+	}
+
+	tree = FromUnsorted(values, cmp.Compare[int], 0)
+	checkTree(t, tree)
+
+	seen := make(map[int]bool)
+	for _, v := range values {
+		seen[v] = true
+	}
+
+	if tree.Len() != len(seen) {
+		t.Errorf("FromUnsorted should have %d unique elements: %d\n", len(seen), tree.Len())
+	}
+}
+
+func TestFromUnsortedDuplicateOrder(t *testing.T) {
+	byKey := func(a, b MyObject) int { return cmp.Compare(a.Key, b.Key) }
+	values := []MyObject{{"b", "1"}, {"a", "1"}, {"b", "2"}, {"a", "2"}, {"b", "3"}}
+
+	// the built tree must match one made by adding the values in order
+	for _, flags := range []byte{AllowDuplicates, AllowDuplicates | DuplicatesFIFO} {
+		added := New(byKey, flags)
+		for _, v := range values {
+			added.Add(v)
+		}
+
+		built := FromUnsorted(values, byKey, flags)
+		checkTree(t, built)
+
+		if !slices.Equal(built.Data(), added.Data()) {
+			t.Errorf("FromUnsorted with flags %d should give %v: %v\n", flags, added.Data(), built.Data())
+		}
+
+		if v := built.Remove(MyObject{Key: "b"}); v == nil || *v != *added.Remove(MyObject{Key: "b"}) {
+			t.Errorf("FromUnsorted with flags %d should remove the same duplicate as Add: %v\n", flags, v)
+		}
+	}
+}
+
+func TestMapFromSorted(t *testing.T) {
+	pairs := []Pair[string, int]{{"a", 1}, {"b", 2}, {"b", 3}, {"c", 4}}
+
+	m := MapFromSorted(pairs, cmp.Compare[string])
+	checkTree(t, &m.t)
+
+	if m.Len() != 3 {
+		t.Errorf("MapFromSorted should have 3 elements: %d\n", m.Len())
+	}
+
+	if v, ok := m.Get("b"); !ok || v != 2 {
+		t.Errorf("MapFromSorted should keep the first b: %v/%v\n", v, ok)
+	}
+
+	m = MapFromUnsorted([]Pair[string, int]{{"z", 1}, {"a", 2}, {"m", 3}, {"a", 4}}, cmp.Compare[string])
+	checkTree(t, &m.t)
+
+	if k := m.Keys(); len(k) != 3 || k[0] != "a" || k[2] != "z" {
+		t.Errorf("MapFromUnsorted keys should be a,m,z: %v\n", k)
+	}
+
+	if v, ok := m.Get("a"); !ok || v != 2 {
+		t.Errorf("MapFromUnsorted should keep the first a: %v/%v\n", v, ok)
+	}
+}
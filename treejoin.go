@@ -0,0 +1,225 @@
+package avltree
+
+// height returns the height of the subtree by following the taller
+// side down from the node.
+func height[T any](node *treeNode[T]) int {
+	h := 0
+	for node != nil {
+		h++
+		if node.bal == leftHigh {
+			node = node.left
+		} else {
+			node = node.right
+		}
+	}
+	return h
+}
+
+// childHeights returns the heights of the left and right subtrees of a
+// node whose subtree has height h.
+func childHeights[T any](node *treeNode[T], h int) (int, int) {
+	switch node.bal {
+	case leftHigh:
+		return h - 1, h - 2
+	case rightHigh:
+		return h - 2, h - 1
+	}
+	return h - 1, h - 1
+}
+
+// fix sets the balance factor and size of a node whose subtrees have
+// heights lh and rh, returning the height of the node.
+func fix[T any](node *treeNode[T], lh, rh int) int {
+	switch {
+	case lh > rh:
+		node.bal = leftHigh
+	case rh > lh:
+		node.bal = rightHigh
+	default:
+		node.bal = equal
+	}
+	node.size = node.leftSize() + node.rightSize()
+	return max(lh, rh) + 1
+}
+
+// rebalance restores the AVL property at a node belonging to o whose
+// subtrees have heights lh and rh differing by at most two, returning
+// the new root and its height.
+func rebalance[T any](node *treeNode[T], lh, rh int, o *owner) (*treeNode[T], int) {
+	if rh > lh+1 {
+		x := node.right.mutable(o)
+		node.right = x
+		xlh, xrh := childHeights(x, rh)
+		if xlh > xrh {
+			x.left = x.left.mutable(o)
+			wlh, wrh := childHeights(x.left, xlh)
+			x = rotateRight(x)
+			node.right = x
+			xrh = fix(x.right, wrh, xrh)
+			xlh = wlh
+			fix(x, xlh, xrh)
+		}
+		node = rotateLeft(node)
+		lh = fix(node.left, lh, xlh)
+		return node, fix(node, lh, xrh)
+	}
+
+	if lh > rh+1 {
+		x := node.left.mutable(o)
+		node.left = x
+		xlh, xrh := childHeights(x, lh)
+		if xrh > xlh {
+			x.right = x.right.mutable(o)
+			wlh, wrh := childHeights(x.right, xrh)
+			x = rotateLeft(x)
+			node.left = x
+			xlh = fix(x.left, xlh, wlh)
+			xrh = wrh
+			fix(x, xlh, xrh)
+		}
+		node = rotateRight(node)
+		rh = fix(node.right, xrh, rh)
+		return node, fix(node, xlh, rh)
+	}
+
+	return node, fix(node, lh, rh)
+}
+
+// join links the subtrees l and r, of heights lh and rh, using k as the
+// middle node. Every element of l must sort before k, and every element
+// of r after it. Changed nodes are made to belong to o. The new root and
+// its height are returned.
+func join[T any](l *treeNode[T], lh int, k *treeNode[T], r *treeNode[T], rh int, o *owner) (*treeNode[T], int) {
+	if lh > rh+1 {
+		l = l.mutable(o)
+		llh, lrh := childHeights(l, lh)
+		l.right, lrh = join(l.right, lrh, k, r, rh, o)
+		return rebalance(l, llh, lrh, o)
+	}
+
+	if rh > lh+1 {
+		r = r.mutable(o)
+		rlh, rrh := childHeights(r, rh)
+		r.left, rlh = join(l, lh, k, r.left, rlh, o)
+		return rebalance(r, rlh, rrh, o)
+	}
+
+	k = k.mutable(o)
+	k.left, k.right = l, r
+	return k, fix(k, lh, rh)
+}
+
+// join2 links the subtrees l and r, of heights lh and rh, where every
+// element of l sorts before those of r.
+func join2[T any](l *treeNode[T], lh int, r *treeNode[T], rh int, o *owner) (*treeNode[T], int) {
+	if r == nil {
+		return l, lh
+	}
+	if l == nil {
+		return r, rh
+	}
+	k := minNode(r)
+	r, _ = removeSuccessor(r, true, o)
+	return join(l, lh, k, r, height(r), o)
+}
+
+// split divides the subtree of height h into the elements before key
+// and the rest, returning the roots and heights of both parts. Changed
+// nodes are made to belong to lo or ro, the owners of the two parts.
+func (t *Tree[T]) split(node *treeNode[T], h int, key T, lo, ro *owner) (*treeNode[T], int, *treeNode[T], int) {
+	if node == nil {
+		return nil, 0, nil, 0
+	}
+
+	nlh, nrh := childHeights(node, h)
+
+	if t.compare(key, node.value) <= 0 {
+		l, lh, r, rh := t.split(node.left, nlh, key, lo, ro)
+		r, rh = join(r, rh, node, node.right, nrh, ro)
+		return l, lh, r, rh
+	}
+
+	l, lh, r, rh := t.split(node.right, nrh, key, lo, ro)
+	l, lh = join(node.left, nlh, node, l, lh, lo)
+	return l, lh, r, rh
+}
+
+// splitAt divides the subtree of height h into the elements before
+// index and the rest, returning the roots and heights of both parts.
+// Changed nodes are made to belong to lo or ro, the owners of the two
+// parts.
+func splitAt[T any](node *treeNode[T], h int, index int, lo, ro *owner) (*treeNode[T], int, *treeNode[T], int) {
+	if node == nil {
+		return nil, 0, nil, 0
+	}
+
+	nlh, nrh := childHeights(node, h)
+
+	if index <= node.leftSize() {
+		l, lh, r, rh := splitAt(node.left, nlh, index, lo, ro)
+		r, rh = join(r, rh, node, node.right, nrh, ro)
+		return l, lh, r, rh
+	}
+
+	l, lh, r, rh := splitAt(node.right, nrh, index-(node.leftSize()+1), lo, ro)
+	l, lh = join(node.left, nlh, node, l, lh, lo)
+	return l, lh, r, rh
+}
+
+// Split removes the elements greater than or equal to key from the tree
+// and returns them as a new tree with the same options and compare
+// function. No elements are copied; this takes O(log n) time.
+func (t *Tree[T]) Split(key T) *Tree[T] {
+	u := New(t.compare, t.treeFlags)
+	u.owner = new(owner)
+	t.root, _, u.root, _ = t.split(t.root, height(t.root), key, t.owner, u.owner)
+	t.mods++
+	return u
+}
+
+// SplitAt removes the elements from the given index onward from the tree
+// and returns them as a new tree with the same options and compare
+// function. No elements are copied; this takes O(log n) time.
+func (t *Tree[T]) SplitAt(index int) *Tree[T] {
+	u := New(t.compare, t.treeFlags)
+	u.owner = new(owner)
+	t.root, _, u.root, _ = splitAt(t.root, height(t.root), index, t.owner, u.owner)
+	t.mods++
+	return u
+}
+
+// Join moves all the elements of other to the end of the tree in
+// O(log n) time, leaving other empty. Every element of other must sort
+// after every element of the tree; if they do not (or they are equal and
+// the tree does not allow duplicates), nothing is moved and false is
+// returned.
+func (t *Tree[T]) Join(other *Tree[T]) bool {
+	if other == t || other.root == nil {
+		return other != t
+	}
+
+	if t.root != nil {
+		code := t.compare(maxNode(t.root).value, minNode(other.root).value)
+		if code > 0 || (code == 0 && (t.treeFlags&AllowDuplicates) == 0) {
+			return false
+		}
+	}
+
+	// The nodes of other may belong to any owner, including one of t's
+	// earlier owners if they were split from t and have since been
+	// cloned, so t takes a new owner and copies what it changes from now on.
+	t.owner = new(owner)
+	t.link(other)
+	return true
+}
+
+// link moves all the elements of other, which must sort after those of t,
+// to the end of the tree, keeping the owner of t. It is only safe if no
+// node of other that belongs to that owner is shared, as when other was
+// split from t and has not been cloned.
+func (t *Tree[T]) link(other *Tree[T]) {
+	t.root, _ = join2(t.root, height(t.root), other.root, height(other.root), t.owner)
+	other.root = nil
+	t.mods++
+	other.mods++
+}
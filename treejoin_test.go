@@ -0,0 +1,225 @@
+package avltree
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	for n := 0; n < 200; n += 7 {
+		for _, key := range []int{-1, 0, n / 3, n / 2, n - 1, n, n + 5} {
+			tree := NewOrdered[int](0)
+			for _, v := range rand.Perm(n) {
+				tree.Add(v)
+			}
+
+			upper := tree.Split(key)
+			checkTree(t, tree)
+			checkTree(t, upper)
+
+			lo := min(max(key, 0), n)
+			if tree.Len() != lo || upper.Len() != n-lo {
+				t.Fatalf("Split(%d) of %d should give %d/%d: %d/%d\n", key, n, lo, n-lo, tree.Len(), upper.Len())
+			}
+
+			if v := tree.Max(); v != nil && *v >= key {
+				t.Errorf("Split(%d) left %d in the lower tree\n", key, *v)
+			}
+
+			if v := upper.Min(); v != nil && *v < key {
+				t.Errorf("Split(%d) moved %d to the upper tree\n", key, *v)
+			}
+
+			if upper.treeFlags != tree.treeFlags {
+				t.Errorf("Split tree flags should be %d: %d\n", tree.treeFlags, upper.treeFlags)
+			}
+
+			// and put it back together
+
+			if !tree.Join(upper) {
+				t.Fatalf("Join after Split(%d) should succeed\n", key)
+			}
+			checkTree(t, tree)
+
+			if tree.Len() != n || upper.Len() != 0 {
+				t.Fatalf("Join should give %d/0: %d/%d\n", n, tree.Len(), upper.Len())
+			}
+		}
+	}
+}
+
+func TestSplitAt(t *testing.T) {
+	tree := NewOrdered[int](AllowDuplicates)
+	for j := 0; j < 1000; j++ {
+		tree.Add(rand.Intn(100))
+	}
+	data := tree.Data()
+
+	for _, index := range []int{0, 1, 500, 999, 1000} {
+		upper := tree.SplitAt(index)
+		checkTree(t, tree)
+		checkTree(t, upper)
+
+		if tree.Len() != index || upper.Len() != 1000-index {
+			t.Fatalf("SplitAt(%d) should give %d/%d: %d/%d\n", index, index, 1000-index, tree.Len(), upper.Len())
+		}
+
+		if v := upper.At(0); v != nil && *v != data[index] {
+			t.Errorf("SplitAt(%d) upper tree should start with %d: %d\n", index, data[index], *v)
+		}
+
+		// duplicates allow equal elements at the seam
+
+		if !tree.Join(upper) {
+			t.Fatalf("Join after SplitAt(%d) should succeed\n", index)
+		}
+	}
+}
+
+func TestJoin(t *testing.T) {
+	// join trees of very different heights
+
+	for _, sizes := range [][2]int{{0, 10}, {10, 0}, {1, 1000}, {1000, 1}, {37, 500}, {500, 37}, {300, 300}} {
+		a := NewOrdered[int](0)
+		b := NewOrdered[int](0)
+		for j := 0; j < sizes[0]; j++ {
+			a.Add(j)
+		}
+		for j := 0; j < sizes[1]; j++ {
+			b.Add(sizes[0] + j)
+		}
+
+		if !a.Join(b) {
+			t.Fatalf("Join of %v should succeed\n", sizes)
+		}
+		checkTree(t, a)
+
+		for j, v := range a.Data() {
+			if v != j {
+				t.Fatalf("Join of %v element %d should be %d: %d\n", sizes, j, j, v)
+			}
+		}
+	}
+
+	// overlapping ranges are refused
+
+	a := NewOrdered[int](0)
+	b := NewOrdered[int](0)
+	a.Add(1)
+	a.Add(5)
+	b.Add(5)
+	b.Add(9)
+
+	if a.Join(b) || a.Len() != 2 || b.Len() != 2 {
+		t.Errorf("Join of overlapping trees should fail: %d/%d\n", a.Len(), b.Len())
+	}
+
+	if a.Join(a) {
+		t.Errorf("Join of a tree with itself should fail\n")
+	}
+}
+
+func TestMapSplit(t *testing.T) {
+	m := NewMapOrdered[string, int]()
+
+	for i, k := range []string{"a", "b", "c", "d", "e"} {
+		m.Add(k, i)
+	}
+
+	upper := m.Split("c")
+
+	if m.Len() != 2 || upper.Len() != 3 {
+		t.Errorf("Split(c) should give 2/3: %d/%d\n", m.Len(), upper.Len())
+	}
+
+	upper.Add("z", 25)
+
+	if v, ok := upper.Get("z"); !ok || v != 25 {
+		t.Errorf("Split map should still be usable: %v/%v\n", v, ok)
+	}
+
+	tail := upper.SplitAt(1)
+
+	if !m.Join(tail) || m.Len() != 5 {
+		t.Errorf("Join of d,e,z after a,b should succeed: %d\n", m.Len())
+	}
+
+	if m.Join(upper) || upper.Len() != 1 {
+		t.Errorf("Join of c after a,b,d,e,z should fail: %d\n", upper.Len())
+	}
+}
+
+func TestCloneSplitJoin(t *testing.T) {
+	tree := NewOrdered[int](0)
+	for i := 0; i < 64; i++ {
+		tree.Add(i)
+	}
+
+	tree.Clone()
+	upper := tree.SplitAt(32)
+	c := upper.Clone()
+	tree.Join(upper)
+	tree.Add(1000)
+	tree.RemoveAt(32)
+
+	if c.Len() != 32 || *c.Min() != 32 {
+		t.Errorf("Clone of split tree should be unchanged: %v\n", c.Data())
+	}
+	checkTree(t, c)
+	checkTree(t, tree)
+
+	// random mix of operations, checked against slices
+	rnd := rand.New(rand.NewSource(1))
+	trees := []*Tree[int]{NewOrdered[int](0)}
+	want := [][]int{nil}
+	for i := 0; i < 500; i++ {
+		trees[0].Add(i)
+		want[0] = append(want[0], i)
+	}
+
+	for step := 0; step < 2000; step++ {
+		j := rnd.Intn(len(trees))
+		switch rnd.Intn(5) {
+		case 0:
+			trees = append(trees, trees[j].Clone())
+			want = append(want, slices.Clone(want[j]))
+		case 1:
+			index := rnd.Intn(len(want[j]) + 1)
+			trees = append(trees, trees[j].SplitAt(index))
+			want = append(want, slices.Clone(want[j][index:]))
+			want[j] = want[j][:index:index]
+		case 2:
+			k := rnd.Intn(len(trees))
+			if trees[j].Join(trees[k]) {
+				want[j] = append(want[j], want[k]...)
+				if k != j {
+					want[k] = nil
+				}
+			}
+		case 3:
+			v := rnd.Intn(500)
+			if _, dupe := trees[j].Add(v); !dupe {
+				i, _ := slices.BinarySearch(want[j], v)
+				want[j] = slices.Insert(want[j], i, v)
+			}
+		case 4:
+			if len(want[j]) > 0 {
+				index := rnd.Intn(len(want[j]))
+				trees[j].RemoveAt(index)
+				want[j] = slices.Delete(want[j], index, index+1)
+			}
+		}
+
+		if len(trees) > 20 {
+			trees, want = trees[1:], want[1:]
+		}
+
+		for k := range trees {
+			if !slices.Equal(trees[k].Data(), want[k]) {
+				t.Fatalf("Step %d: tree %d should hold %v: %v\n", step, k, want[k], trees[k].Data())
+			}
+			checkTree(t, trees[k])
+		}
+	}
+}
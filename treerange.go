@@ -0,0 +1,216 @@
+package avltree
+
+import "iter"
+
+// Bounds controls whether the ends of a key range are included.
+type Bounds byte
+
+// range bounds
+const (
+	IncludeLow  Bounds = 1 << iota // the low key is part of the range
+	IncludeHigh                    // the high key is part of the range
+
+	Open     Bounds = 0                        // (lo, hi)
+	HalfOpen        = IncludeLow               // [lo, hi)
+	Closed          = IncludeLow | IncludeHigh // [lo, hi]
+)
+
+// rangeData is used when iterating a range of keys in the tree.
+type rangeData[T any] struct {
+	lo, hi  *T             // range bounds; nil means unbounded
+	bounds  Bounds         // which bounds are included
+	compare compareFunc[T] // Comparison function
+	iter    iterateFunc[T] // function to call for each element
+}
+
+// aboveLow reports whether the value is within the low bound.
+func (d *rangeData[T]) aboveLow(v T) bool {
+	if d.lo == nil {
+		return true
+	}
+	code := d.compare(v, *d.lo)
+	return code > 0 || (code == 0 && d.bounds&IncludeLow != 0)
+}
+
+// belowHigh reports whether the value is within the high bound.
+func (d *rangeData[T]) belowHigh(v T) bool {
+	if d.hi == nil {
+		return true
+	}
+	code := d.compare(v, *d.hi)
+	return code < 0 || (code == 0 && d.bounds&IncludeHigh != 0)
+}
+
+// iterate recursively traverses the part of the tree within the bounds,
+// skipping subtrees that lie entirely outside of them.
+func (d *rangeData[T]) iterate(node *treeNode[T]) bool {
+	if node == nil {
+		return true
+	}
+
+	low := d.aboveLow(node.value)
+	high := d.belowHigh(node.value)
+
+	if low {
+		if !d.iterate(node.left) {
+			return false
+		}
+	}
+
+	if low && high {
+		if !d.iter(node.value) {
+			return false
+		}
+	}
+
+	if high {
+		if !d.iterate(node.right) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// DoBetween calls function f for each element of the tree between lo and
+// hi, in order. The bounds determine whether lo and hi themselves are
+// included. The function should not change the structure of the tree
+// underfoot.
+func (t *Tree[T]) DoBetween(lo, hi T, bounds Bounds, f func(T) bool) {
+	if f != nil && t.root != nil {
+		d := &rangeData[T]{&lo, &hi, bounds, t.compare, t.guard(f)}
+		d.iterate(t.root)
+	}
+}
+
+// DoFrom calls function f for each element of the tree greater than or
+// equal to key, in order. The function should not change the structure
+// of the tree underfoot.
+func (t *Tree[T]) DoFrom(key T, f func(T) bool) {
+	if f != nil && t.root != nil {
+		d := &rangeData[T]{&key, nil, IncludeLow, t.compare, t.guard(f)}
+		d.iterate(t.root)
+	}
+}
+
+// Range returns an iterator over the elements of the tree between lo and
+// hi, in order. The bounds determine whether lo and hi themselves are
+// included.
+func (t *Tree[T]) Range(lo, hi T, bounds Bounds) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		t.DoBetween(lo, hi, bounds, yield)
+	}
+}
+
+// From returns an iterator over the elements of the tree greater than or
+// equal to key, in order.
+func (t *Tree[T]) From(key T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		t.DoFrom(key, yield)
+	}
+}
+
+// indexRangeData is used when iterating a range of indexes in the tree.
+type indexRangeData[T any] struct {
+	start, end int            // index range [start, end)
+	iter       iterateFunc[T] // function to call for each element
+}
+
+// iterate recursively traverses the part of the tree within the index
+// range, using the subtree sizes to skip the rest. The base is the index
+// of the leftmost element of the subtree.
+func (d *indexRangeData[T]) iterate(node *treeNode[T], base int) bool {
+	if node == nil {
+		return true
+	}
+
+	index := base + node.leftSize()
+
+	if d.start < index {
+		if !d.iterate(node.left, base) {
+			return false
+		}
+	}
+
+	if index >= d.start && index < d.end {
+		if !d.iter(node.value) {
+			return false
+		}
+	}
+
+	if index+1 < d.end {
+		if !d.iterate(node.right, index+1) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// clampRange limits the index range [start, end) to the elements of the tree.
+func (t *Tree[T]) clampRange(start, end int) (int, int) {
+	if start < 0 {
+		start = 0
+	}
+	if end > t.Len() {
+		end = t.Len()
+	}
+	if end < start {
+		end = start
+	}
+	return start, end
+}
+
+// DoRange calls function f for each element of the tree with an index
+// in [start, end), in order. Indexes outside the tree are ignored. The
+// function should not change the structure of the tree underfoot.
+func (t *Tree[T]) DoRange(start, end int, f func(T) bool) {
+	start, end = t.clampRange(start, end)
+	if f != nil && start < end {
+		d := &indexRangeData[T]{start, end, t.guard(f)}
+		d.iterate(t.root, 0)
+	}
+}
+
+// Slice returns the elements with an index in [start, end) as a slice.
+// Indexes outside the tree are ignored.
+func (t *Tree[T]) Slice(start, end int) []T {
+	start, end = t.clampRange(start, end)
+	arr := make([]T, 0, end-start)
+	t.DoRange(start, end, func(v T) bool {
+		arr = append(arr, v)
+		return true
+	})
+	return arr
+}
+
+// countBelow counts the elements less than key, or less than or equal
+// to key if inclusive is set, using the subtree sizes.
+func (t *Tree[T]) countBelow(key T, inclusive bool) int {
+	count := 0
+	node := t.root
+
+	for node != nil {
+		code := t.compare(key, node.value)
+		if code < 0 || (code == 0 && !inclusive) {
+			node = node.left
+		} else {
+			count += node.leftSize() + 1
+			node = node.right
+		}
+	}
+
+	return count
+}
+
+// CountRange returns the number of elements between lo and hi without
+// visiting them. The bounds determine whether lo and hi themselves are
+// included.
+func (t *Tree[T]) CountRange(lo, hi T, bounds Bounds) int {
+	below := t.countBelow(lo, bounds&IncludeLow == 0)
+	upTo := t.countBelow(hi, bounds&IncludeHigh != 0)
+	if upTo > below {
+		return upTo - below
+	}
+	return 0
+}
@@ -0,0 +1,279 @@
+package avltree
+
+import (
+	"cmp"
+	"math/rand"
+	"testing"
+)
+
+func TestRange(t *testing.T) {
+	tree := NewOrdered[int](0)
+
+	for j := 0; j < 100; j++ {
+		tree.Add(j)
+	}
+
+	tests := []struct {
+		lo, hi int
+		bounds Bounds
+		first  int
+		count  int
+	}{
+		{10, 20, HalfOpen, 10, 10},
+		{10, 20, Closed, 10, 11},
+		{10, 20, Open, 11, 9},
+		{10, 20, IncludeHigh, 11, 10},
+		{-5, 3, HalfOpen, 0, 3},
+		{95, 200, Closed, 95, 5},
+		{20, 10, Closed, 0, 0},
+		{10, 10, HalfOpen, 0, 0},
+		{10, 10, Closed, 10, 1},
+	}
+
+	for _, tc := range tests {
+		n := 0
+		prev := tc.first - 1
+		for v := range tree.Range(tc.lo, tc.hi, tc.bounds) {
+			if v != prev+1 {
+				t.Errorf("Range(%d, %d, %d) expected %d, got %d\n", tc.lo, tc.hi, tc.bounds, prev+1, v)
+			}
+			prev = v
+			n++
+		}
+		if n != tc.count {
+			t.Errorf("Range(%d, %d, %d) should have %d elements, got %d\n", tc.lo, tc.hi, tc.bounds, tc.count, n)
+		}
+	}
+
+	// early exit
+
+	n := 0
+	tree.DoBetween(10, 90, Closed, func(v int) bool {
+		n++
+		return v < 15
+	})
+
+	if n != 6 {
+		t.Errorf("DoBetween should stop after 6 elements, got %d\n", n)
+	}
+
+	// test DoFrom / From
+
+	x := 90
+	tree.DoFrom(90, func(v int) bool {
+		if v != x {
+			t.Errorf("DoFrom expected %d, got %d\n", x, v)
+		}
+		x++
+		return true
+	})
+
+	if x != 100 {
+		t.Errorf("DoFrom ran wrong number of elements, expected 10, got %d\n", x-90)
+	}
+
+	n = 0
+	for range tree.From(1000) {
+		n++
+	}
+
+	if n != 0 {
+		t.Errorf("From past the end should be empty, got %d\n", n)
+	}
+}
+
+func TestRangePrunes(t *testing.T) {
+	calls := 0
+	tree := New[int](func(a, b int) int {
+		calls++
+		return cmp.Compare(a, b)
+	}, AllowDuplicates)
+
+	for j := 0; j < 10000; j++ {
+		tree.Add(rand.Intn(1000))
+	}
+
+	var want []int
+	tree.Do(func(v int) bool {
+		if v >= 500 && v < 510 {
+			want = append(want, v)
+		}
+		return true
+	})
+
+	calls = 0
+	var got []int
+	for v := range tree.Range(500, 510, HalfOpen) {
+		got = append(got, v)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("Range returned %d elements, expected %d\n", len(got), len(want))
+	}
+
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("Range element %d expected %d, got %d\n", i, want[i], got[i])
+		}
+	}
+
+	if calls > 2*(len(want)+4*tree.Height()) {
+		t.Errorf("Range did not prune the tree, %d comparisons for %d elements\n", calls, len(want))
+	}
+}
+
+func TestMapRange(t *testing.T) {
+	m := NewMapOrdered[string, int]()
+
+	for i, k := range []string{"a", "b", "c", "d", "e"} {
+		m.Add(k, i)
+	}
+
+	s := ""
+	for k, v := range m.Range("b", "d", HalfOpen) {
+		s += k
+		if v != int(k[0]-'a') {
+			t.Errorf("Range value for %s should be %d, got %d\n", k, k[0]-'a', v)
+		}
+	}
+
+	if s != "bc" {
+		t.Errorf("Range(b, d) expected bc, got %s\n", s)
+	}
+
+	s = ""
+	m.DoFrom("c", func(k string, v int) bool {
+		s += k
+		return true
+	})
+
+	if s != "cde" {
+		t.Errorf("DoFrom(c) expected cde, got %s\n", s)
+	}
+}
+
+func TestIndexRange(t *testing.T) {
+	tree := NewOrdered[int](0)
+
+	if s := tree.Slice(0, 10); len(s) != 0 {
+		t.Errorf("Slice of empty tree should be empty: %v\n", s)
+	}
+
+	for j := 0; j < 1000; j++ {
+		tree.Add(j * 2)
+	}
+
+	tests := []struct {
+		start, end int
+		first      int
+		count      int
+	}{
+		{0, 10, 0, 10},
+		{100, 150, 100, 50},
+		{990, 2000, 990, 10},
+		{-10, 5, 0, 5},
+		{500, 500, 0, 0},
+		{600, 500, 0, 0},
+		{1000, 1010, 0, 0},
+	}
+
+	for _, tc := range tests {
+		s := tree.Slice(tc.start, tc.end)
+		if len(s) != tc.count {
+			t.Errorf("Slice(%d, %d) should have %d elements, got %d\n", tc.start, tc.end, tc.count, len(s))
+			continue
+		}
+		for i, v := range s {
+			if v != (tc.first+i)*2 {
+				t.Errorf("Slice(%d, %d) element %d expected %d, got %d\n", tc.start, tc.end, i, (tc.first+i)*2, v)
+			}
+		}
+	}
+
+	// early exit
+
+	n := 0
+	tree.DoRange(10, 20, func(v int) bool {
+		n++
+		return n < 3
+	})
+
+	if n != 3 {
+		t.Errorf("DoRange should stop after 3 elements, got %d\n", n)
+	}
+}
+
+func TestMapIndexRange(t *testing.T) {
+	m := NewMapOrdered[string, int]()
+
+	for i, k := range []string{"a", "b", "c", "d", "e"} {
+		m.Add(k, i)
+	}
+
+	p := m.Pairs(1, 3)
+	if len(p) != 2 || p[0].Key != "b" || p[1].Key != "c" {
+		t.Errorf("Pairs(1, 3) expected b,c, got %v\n", p)
+	}
+
+	k := m.SliceKeys(3, 10)
+	if len(k) != 2 || k[0] != "d" || k[1] != "e" {
+		t.Errorf("SliceKeys(3, 10) expected d,e, got %v\n", k)
+	}
+
+	v := m.SliceValues(0, 2)
+	if len(v) != 2 || v[0] != 0 || v[1] != 1 {
+		t.Errorf("SliceValues(0, 2) expected 0,1, got %v\n", v)
+	}
+
+	s := ""
+	m.DoRange(2, 4, func(k string, v int) bool {
+		s += k
+		return true
+	})
+
+	if s != "cd" {
+		t.Errorf("DoRange(2, 4) expected cd, got %s\n", s)
+	}
+}
+
+func TestCountRange(t *testing.T) {
+	tree := NewOrdered[int](AllowDuplicates)
+
+	if n := tree.CountRange(0, 10, Closed); n != 0 {
+		t.Errorf("CountRange on empty tree should be 0: %d\n", n)
+	}
+
+	for j := 0; j < 5000; j++ {
+		tree.Add(rand.Intn(500))
+	}
+
+	for j := 0; j < 200; j++ {
+		lo := rand.Intn(520) - 10
+		hi := rand.Intn(520) - 10
+		for _, b := range []Bounds{Open, HalfOpen, IncludeHigh, Closed} {
+			want := 0
+			for range tree.Range(lo, hi, b) {
+				want++
+			}
+			if n := tree.CountRange(lo, hi, b); n != want {
+				t.Errorf("CountRange(%d, %d, %d) expected %d, got %d\n", lo, hi, b, want, n)
+			}
+		}
+	}
+}
+
+func TestMapCountRange(t *testing.T) {
+	m := NewMapOrdered[int, string]()
+
+	for j := 0; j < 100; j += 10 {
+		m.Add(j, "")
+	}
+
+	if n := m.CountRange(10, 50, HalfOpen); n != 4 {
+		t.Errorf("CountRange(10, 50) expected 4, got %d\n", n)
+	}
+
+	if n := m.CountRange(15, 55, Closed); n != 4 {
+		t.Errorf("CountRange(15, 55) expected 4, got %d\n", n)
+	}
+}
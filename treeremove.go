@@ -4,6 +4,7 @@ package avltree
 type removeData[T any] struct {
 	lookingFor T              // Item to remove
 	compare    compareFunc[T] // Comparison function
+	owner      *owner         // owner of nodes that may be changed
 }
 
 func findPredecessor[T any](node *treeNode[T]) *treeNode[T] {
@@ -19,8 +20,9 @@ func findPredecessor[T any](node *treeNode[T]) *treeNode[T] {
 	return nil
 }
 
-func remLeftSubBalance[T any](node *treeNode[T], shorter bool) (*treeNode[T], bool) {
-	q := node.right // q: root of taller subtree
+func remLeftSubBalance[T any](node *treeNode[T], shorter bool, o *owner) (*treeNode[T], bool) {
+	q := node.right.mutable(o) // q: root of taller subtree
+	node.right = q
 	var w *treeNode[T]
 
 	switch q.bal {
@@ -34,7 +36,8 @@ func remLeftSubBalance[T any](node *treeNode[T], shorter bool) (*treeNode[T], bo
 		q.bal = equal // q will be the new root node
 		node = rotateLeft(node)
 	case leftHigh:
-		w = q.left
+		w = q.left.mutable(o)
+		q.left = w
 		if w.bal == leftHigh {
 			q.bal = rightHigh
 		} else {
@@ -54,8 +57,9 @@ func remLeftSubBalance[T any](node *treeNode[T], shorter bool) (*treeNode[T], bo
 	return node, shorter
 }
 
-func remRightSubBalance[T any](node *treeNode[T], shorter bool) (*treeNode[T], bool) {
-	q := node.left // q: root of taller subtree
+func remRightSubBalance[T any](node *treeNode[T], shorter bool, o *owner) (*treeNode[T], bool) {
+	q := node.left.mutable(o) // q: root of taller subtree
+	node.left = q
 	var w *treeNode[T]
 
 	switch q.bal {
@@ -69,7 +73,8 @@ func remRightSubBalance[T any](node *treeNode[T], shorter bool) (*treeNode[T], b
 		q.bal = equal // q will be the new root node
 		node = rotateRight(node)
 	case rightHigh:
-		w = q.right
+		w = q.right.mutable(o)
+		q.right = w
 		if w.bal == rightHigh {
 			q.bal = leftHigh
 		} else {
@@ -89,12 +94,13 @@ func remRightSubBalance[T any](node *treeNode[T], shorter bool) (*treeNode[T], b
 	return node, shorter
 }
 
-func removePredecessor[T any](node *treeNode[T], shorter bool) (*treeNode[T], bool) {
+func removePredecessor[T any](node *treeNode[T], shorter bool, o *owner) (*treeNode[T], bool) {
 	if node.right != nil {
-		node.right, shorter = removePredecessor(node.right, shorter)
+		node = node.mutable(o)
+		node.right, shorter = removePredecessor(node.right, shorter, o)
 
 		if shorter { // left subtree was shortened
-			node, shorter = remRightBalance(node, shorter)
+			node, shorter = remRightBalance(node, shorter, o)
 		}
 
 		node.size = node.leftSize() + node.rightSize()
@@ -105,7 +111,24 @@ func removePredecessor[T any](node *treeNode[T], shorter bool) (*treeNode[T], bo
 	return node, shorter
 }
 
-func remLeftBalance[T any](node *treeNode[T], shorter bool) (*treeNode[T], bool) {
+func removeSuccessor[T any](node *treeNode[T], shorter bool, o *owner) (*treeNode[T], bool) {
+	if node.left != nil {
+		node = node.mutable(o)
+		node.left, shorter = removeSuccessor(node.left, shorter, o)
+
+		if shorter { // left subtree was shortened
+			node, shorter = remLeftBalance(node, shorter, o)
+		}
+
+		node.size = node.leftSize() + node.rightSize()
+	} else {
+		node = remNode(node)
+	}
+
+	return node, shorter
+}
+
+func remLeftBalance[T any](node *treeNode[T], shorter bool, o *owner) (*treeNode[T], bool) {
 
 	switch node.bal {
 	case equal: // one subtree shortened
@@ -114,12 +137,12 @@ func remLeftBalance[T any](node *treeNode[T], shorter bool) (*treeNode[T], bool)
 	case leftHigh: // taller subtree shortened
 		node.bal = equal // now it's equal
 	case rightHigh: // shorter subtree shortened
-		node, shorter = remLeftSubBalance(node, shorter)
+		node, shorter = remLeftSubBalance(node, shorter, o)
 	}
 	return node, shorter
 }
 
-func remRightBalance[T any](node *treeNode[T], shorter bool) (*treeNode[T], bool) {
+func remRightBalance[T any](node *treeNode[T], shorter bool, o *owner) (*treeNode[T], bool) {
 
 	switch node.bal {
 	case equal: // one subtree shortened
@@ -128,7 +151,7 @@ func remRightBalance[T any](node *treeNode[T], shorter bool) (*treeNode[T], bool
 	case rightHigh: // taller subtree shortened
 		node.bal = equal // now it's equal
 	case leftHigh: // shorter subtree shortened
-		node, shorter = remRightSubBalance(node, shorter)
+		node, shorter = remRightSubBalance(node, shorter, o)
 	}
 	return node, shorter
 }
@@ -149,6 +172,7 @@ func (d *removeData[T]) remove(node **treeNode[T], shorter *bool) *T {
 	*shorter = true // default: shorter
 	var ptr *T
 
+	*node = (*node).mutable(d.owner)
 	code := d.compare(d.lookingFor, (*node).value)
 
 	if code < 0 {
@@ -156,7 +180,7 @@ func (d *removeData[T]) remove(node **treeNode[T], shorter *bool) *T {
 			ptr = d.remove(&((*node).left), shorter)
 
 			if *shorter && ptr != nil { // left subtree was shortened
-				*node, *shorter = remLeftBalance(*node, *shorter)
+				*node, *shorter = remLeftBalance(*node, *shorter, d.owner)
 			}
 		}
 	} else if code > 0 {
@@ -164,7 +188,7 @@ func (d *removeData[T]) remove(node **treeNode[T], shorter *bool) *T {
 			ptr = d.remove(&((*node).right), shorter)
 
 			if *shorter && ptr != nil { // left subtree was shortened
-				*node, *shorter = remRightBalance(*node, *shorter)
+				*node, *shorter = remRightBalance(*node, *shorter, d.owner)
 			}
 		}
 	} else {
@@ -172,12 +196,14 @@ func (d *removeData[T]) remove(node **treeNode[T], shorter *bool) *T {
 
 		if (*node).left != nil && (*node).right != nil { // do the switch to find the prev.
 			// node with only one subtree
+			value := (*node).value // the node is reused, so return a copy
+			ptr = &value
 			pred := findPredecessor(*node)
 			(*node).value = pred.value
-			(*node).left, *shorter = removePredecessor((*node).left, *shorter)
+			(*node).left, *shorter = removePredecessor((*node).left, *shorter, d.owner)
 
 			if *shorter { // left subtree was shortened
-				*node, *shorter = remLeftBalance(*node, *shorter)
+				*node, *shorter = remLeftBalance(*node, *shorter, d.owner)
 			}
 		} else { // we found the node; it has 1 subtree
 			*node = remNode(*node)
@@ -191,28 +217,43 @@ func (d *removeData[T]) remove(node **treeNode[T], shorter *bool) *T {
 	return ptr
 }
 
-// Remove removes the element matching the given value.
+// Remove removes the element matching the given value. If the tree
+// allows duplicates, the first matching element in order is removed:
+// the most recently added one, or the oldest one if the DuplicatesFIFO
+// flag is set.
 func (t *Tree[T]) Remove(ptr T) *T {
+	if (t.treeFlags & AllowDuplicates) != 0 {
+		if start, end := t.EqualRange(ptr); end > start {
+			return t.RemoveAt(start)
+		}
+		return nil
+	}
+
 	if t.root != nil {
-		d := &removeData[T]{ptr, t.compare}
+		d := &removeData[T]{ptr, t.compare, t.owner}
 		var shorter bool
-		return d.remove(&(t.root), &shorter)
+		val := d.remove(&(t.root), &shorter)
+		if val != nil {
+			t.mods++
+		}
+		return val
 	}
 
 	return nil
 }
 
-func remove[T any](node **treeNode[T], index int, shorter *bool) *T {
+func remove[T any](node **treeNode[T], index int, shorter *bool, o *owner) *T {
 
 	*shorter = true // default: shorter
 	var ptr *T
 
+	*node = (*node).mutable(o)
 	if index < (*node).leftSize() {
 		if (*node).left != nil {
-			ptr = remove(&((*node).left), index, shorter)
+			ptr = remove(&((*node).left), index, shorter, o)
 
 			if *shorter && ptr != nil { // left subtree was shortened
-				*node, *shorter = remLeftBalance(*node, *shorter)
+				*node, *shorter = remLeftBalance(*node, *shorter, o)
 			}
 		}
 	} else if index == (*node).leftSize() {
@@ -220,22 +261,24 @@ func remove[T any](node **treeNode[T], index int, shorter *bool) *T {
 
 		if (*node).left != nil && (*node).right != nil { // do the switch to find the prev.
 			// node with only one subtree
+			value := (*node).value // the node is reused, so return a copy
+			ptr = &value
 			pred := findPredecessor(*node)
 			(*node).value = pred.value
-			(*node).left, *shorter = removePredecessor((*node).left, *shorter)
+			(*node).left, *shorter = removePredecessor((*node).left, *shorter, o)
 
 			if *shorter { // left subtree was shortened
-				*node, *shorter = remLeftBalance(*node, *shorter)
+				*node, *shorter = remLeftBalance(*node, *shorter, o)
 			}
 		} else { // we found the node; it has 1 subtree
 			*node = remNode(*node)
 		}
 	} else {
 		if (*node).right != nil {
-			ptr = remove(&((*node).right), index-((*node).leftSize()+1), shorter)
+			ptr = remove(&((*node).right), index-((*node).leftSize()+1), shorter, o)
 
 			if *shorter && ptr != nil { // left subtree was shortened
-				*node, *shorter = remRightBalance(*node, *shorter)
+				*node, *shorter = remRightBalance(*node, *shorter, o)
 			}
 		}
 	}
@@ -251,8 +294,101 @@ func remove[T any](node **treeNode[T], index int, shorter *bool) *T {
 func (t *Tree[T]) RemoveAt(index int) *T {
 	if t.root != nil && index < t.root.size+1 && index >= 0 {
 		var shorter bool
-		return remove(&(t.root), index, &shorter)
+		t.mods++
+		return remove(&(t.root), index, &shorter, t.owner)
+	}
+
+	return nil
+}
+
+// PopMin removes and returns the smallest element in the tree,
+// or nil if the tree is empty.
+func (t *Tree[T]) PopMin() *T {
+	if t.root != nil {
+		value := minNode(t.root).value // the node may be shared, so return a copy
+		t.root, _ = removeSuccessor(t.root, true, t.owner)
+		t.mods++
+		return &value
 	}
 
 	return nil
 }
+
+// PopMax removes and returns the largest element in the tree,
+// or nil if the tree is empty.
+func (t *Tree[T]) PopMax() *T {
+	if t.root != nil {
+		value := maxNode(t.root).value // the node may be shared, so return a copy
+		t.root, _ = removePredecessor(t.root, true, t.owner)
+		t.mods++
+		return &value
+	}
+
+	return nil
+}
+
+// RemoveAll removes all the elements equal to key, returning how many
+// were removed. This takes O(log n) time.
+func (t *Tree[T]) RemoveAll(key T) int {
+	start, end := t.EqualRange(key)
+	return t.RemoveIndexRange(start, end)
+}
+
+// RemoveFunc removes the first element in order that is equal to key and
+// for which match returns true, returning it, or nil if there is none.
+// This allows a tree with duplicates to be used as an index over
+// non-unique sort keys.
+func (t *Tree[T]) RemoveFunc(key T, match func(T) bool) *T {
+	if index := t.indexFunc(key, match); index >= 0 {
+		return t.RemoveAt(index)
+	}
+	return nil
+}
+
+// RemoveIndexRange removes the elements with an index in [start, end),
+// returning how many were removed. Indexes outside the tree are ignored.
+// This takes O(log n) time.
+func (t *Tree[T]) RemoveIndexRange(start, end int) int {
+	start, end = t.clampRange(start, end)
+	if end > start {
+		rest := t.SplitAt(start)
+		t.link(rest.SplitAt(end - start)) // rest is discarded, so t keeps its owner
+	}
+	return end - start
+}
+
+// RemoveRange removes the elements between lo and hi, returning how many
+// were removed. The bounds determine whether lo and hi themselves are
+// included. This takes O(log n) time.
+func (t *Tree[T]) RemoveRange(lo, hi T, bounds Bounds) int {
+	start := t.countBelow(lo, bounds&IncludeLow == 0)
+	end := t.countBelow(hi, bounds&IncludeHigh != 0)
+	return t.RemoveIndexRange(start, end)
+}
+
+// RemoveIf removes all the elements for which f returns true, returning
+// how many were removed. The tree is rebuilt in O(n) time.
+func (t *Tree[T]) RemoveIf(f func(T) bool) int {
+	arr := make([]T, 0, t.Len())
+	t.Do(func(v T) bool {
+		if !f(v) {
+			arr = append(arr, v)
+		}
+		return true
+	})
+
+	removed := t.Len() - len(arr)
+	if removed > 0 {
+		t.root, _ = build(arr, t.owner)
+		t.mods++
+	}
+	return removed
+}
+
+// Retain removes all the elements for which f returns false, returning
+// how many were removed. The tree is rebuilt in O(n) time.
+func (t *Tree[T]) Retain(f func(T) bool) int {
+	return t.RemoveIf(func(v T) bool {
+		return !f(v)
+	})
+}
@@ -0,0 +1,155 @@
+package avltree
+
+import "iter"
+
+// neighborData is used when searching the tree for the closest
+// element to a key.
+type neighborData[T any] struct {
+	lookingFor T              // item we are searching around
+	compare    compareFunc[T] // Comparison function
+	below      bool           // look for elements before the key
+	inclusive  bool           // elements equal to the key qualify
+}
+
+// neighbor scans the tree for the closest qualifying node to the
+// key, returning the node and its index, or nil and -1 if there is none.
+func (d *neighborData[T]) neighbor(node *treeNode[T]) (*treeNode[T], int) {
+	var found *treeNode[T]
+	index := -1
+	base := 0
+
+	for node != nil {
+		code := d.compare(d.lookingFor, node.value)
+		if d.below {
+			if code > 0 || (code == 0 && d.inclusive) {
+				found, index = node, base+node.leftSize()
+				base += node.leftSize() + 1
+				node = node.right
+			} else {
+				node = node.left
+			}
+		} else {
+			if code < 0 || (code == 0 && d.inclusive) {
+				found, index = node, base+node.leftSize()
+				node = node.left
+			} else {
+				base += node.leftSize() + 1
+				node = node.right
+			}
+		}
+	}
+
+	return found, index
+}
+
+// search returns the closest qualifying element to key and its index.
+func (t *Tree[T]) search(key T, below, inclusive bool) (*T, int) {
+	d := &neighborData[T]{key, t.compare, below, inclusive}
+	node, index := d.neighbor(t.root)
+	if node != nil {
+		return &node.value, index
+	}
+	return nil, -1
+}
+
+// Floor returns the greatest element less than or equal to key, along
+// with its index. If there is no such element, nil and -1 are returned.
+func (t *Tree[T]) Floor(key T) (*T, int) {
+	return t.search(key, true, true)
+}
+
+// Ceiling returns the smallest element greater than or equal to key, along
+// with its index. If there is no such element, nil and -1 are returned.
+func (t *Tree[T]) Ceiling(key T) (*T, int) {
+	return t.search(key, false, true)
+}
+
+// Lower returns the greatest element strictly less than key, along
+// with its index. If there is no such element, nil and -1 are returned.
+func (t *Tree[T]) Lower(key T) (*T, int) {
+	return t.search(key, true, false)
+}
+
+// Higher returns the smallest element strictly greater than key, along
+// with its index. If there is no such element, nil and -1 are returned.
+func (t *Tree[T]) Higher(key T) (*T, int) {
+	return t.search(key, false, false)
+}
+
+// rank descends the tree counting the elements strictly less than key,
+// noting whether an element equal to key was seen along the way.
+func (t *Tree[T]) rank(key T) (int, bool) {
+	base := 0
+	found := false
+	node := t.root
+
+	for node != nil {
+		code := t.compare(key, node.value)
+		if code <= 0 {
+			if code == 0 {
+				found = true
+			}
+			node = node.left
+		} else {
+			base += node.leftSize() + 1
+			node = node.right
+		}
+	}
+
+	return base, found
+}
+
+// Rank returns the number of elements strictly less than key. The key
+// does not need to be present in the tree.
+func (t *Tree[T]) Rank(key T) int {
+	r, _ := t.rank(key)
+	return r
+}
+
+// IndexOf returns the index of the element matching key. If duplicates
+// are present, the index of the first one is returned. If there is no
+// matching element, -1 and false are returned.
+func (t *Tree[T]) IndexOf(key T) (int, bool) {
+	r, found := t.rank(key)
+	if found {
+		return r, true
+	}
+	return -1, false
+}
+
+// EqualRange returns the index range [start, end) holding the elements
+// equal to key. If there are none, start and end are both the index
+// where key would be inserted.
+func (t *Tree[T]) EqualRange(key T) (start, end int) {
+	return t.countBelow(key, false), t.countBelow(key, true)
+}
+
+// indexFunc returns the index of the first element in order that is equal
+// to key and for which match returns true, or -1 if there is none.
+func (t *Tree[T]) indexFunc(key T, match func(T) bool) int {
+	start, end := t.EqualRange(key)
+	index := -1
+	i := start
+	t.DoRange(start, end, func(v T) bool {
+		if match(v) {
+			index = i
+			return false
+		}
+		i++
+		return true
+	})
+	return index
+}
+
+// CountEqual returns the number of elements equal to key in O(log n) time.
+func (t *Tree[T]) CountEqual(key T) int {
+	start, end := t.EqualRange(key)
+	return end - start
+}
+
+// FindAll returns an iterator over all the elements equal to key, in order.
+func (t *Tree[T]) FindAll(key T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		t.DoBetween(key, key, Closed, yield)
+	}
+}
@@ -0,0 +1,222 @@
+package avltree
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestNeighbors(t *testing.T) {
+	tree := NewOrdered[int](0)
+
+	if v, i := tree.Floor(10); v != nil || i != -1 {
+		t.Errorf("Floor of empty tree should be nil/-1: %v/%d\n", v, i)
+	}
+
+	// even numbers 0..98
+	for j := 0; j < 100; j += 2 {
+		tree.Add(j)
+	}
+
+	tests := []struct {
+		name  string
+		f     func(int) (*int, int)
+		key   int
+		value int
+		index int
+	}{
+		{"Floor", tree.Floor, 10, 10, 5},
+		{"Floor", tree.Floor, 11, 10, 5},
+		{"Floor", tree.Floor, -1, 0, -1},
+		{"Floor", tree.Floor, 200, 98, 49},
+		{"Ceiling", tree.Ceiling, 10, 10, 5},
+		{"Ceiling", tree.Ceiling, 11, 12, 6},
+		{"Ceiling", tree.Ceiling, -1, 0, 0},
+		{"Ceiling", tree.Ceiling, 99, 0, -1},
+		{"Lower", tree.Lower, 10, 8, 4},
+		{"Lower", tree.Lower, 11, 10, 5},
+		{"Lower", tree.Lower, 0, 0, -1},
+		{"Higher", tree.Higher, 10, 12, 6},
+		{"Higher", tree.Higher, 11, 12, 6},
+		{"Higher", tree.Higher, 98, 0, -1},
+	}
+
+	for _, tc := range tests {
+		v, i := tc.f(tc.key)
+		if tc.index < 0 {
+			if v != nil || i != -1 {
+				t.Errorf("%s(%d) should be nil/-1: %v/%d\n", tc.name, tc.key, v, i)
+			}
+			continue
+		}
+		if v == nil || *v != tc.value || i != tc.index {
+			t.Errorf("%s(%d) should be %d/%d: %v/%d\n", tc.name, tc.key, tc.value, tc.index, v, i)
+			continue
+		}
+		if a := tree.At(i); a == nil || *a != *v {
+			t.Errorf("%s(%d) index %d does not match At: %v\n", tc.name, tc.key, i, a)
+		}
+	}
+
+	// duplicates: Floor finds the last equal element, Ceiling the first
+
+	tree = NewOrdered[int](AllowDuplicates)
+	for _, v := range []int{1, 2, 2, 2, 3} {
+		tree.Add(v)
+	}
+
+	if v, i := tree.Floor(2); v == nil || *v != 2 || i != 3 {
+		t.Errorf("Floor(2) with duplicates should be 2/3: %v/%d\n", v, i)
+	}
+
+	if v, i := tree.Ceiling(2); v == nil || *v != 2 || i != 1 {
+		t.Errorf("Ceiling(2) with duplicates should be 2/1: %v/%d\n", v, i)
+	}
+}
+
+func TestMapNeighbors(t *testing.T) {
+	m := NewMapOrdered[string, int]()
+
+	m.Add("b", 2)
+	m.Add("d", 4)
+	m.Add("f", 6)
+
+	if p, i := m.Floor("c"); p == nil || p.Key != "b" || p.Value != 2 || i != 0 {
+		t.Errorf("Floor(c) should be b/0: %v/%d\n", p, i)
+	}
+
+	if p, i := m.Ceiling("c"); p == nil || p.Key != "d" || p.Value != 4 || i != 1 {
+		t.Errorf("Ceiling(c) should be d/1: %v/%d\n", p, i)
+	}
+
+	if p, i := m.Lower("d"); p == nil || p.Key != "b" || i != 0 {
+		t.Errorf("Lower(d) should be b/0: %v/%d\n", p, i)
+	}
+
+	if p, i := m.Higher("f"); p != nil || i != -1 {
+		t.Errorf("Higher(f) should be nil/-1: %v/%d\n", p, i)
+	}
+}
+
+func TestRank(t *testing.T) {
+	tree := NewOrdered[int](0)
+
+	if r := tree.Rank(5); r != 0 {
+		t.Errorf("Rank on empty tree should be 0: %d\n", r)
+	}
+
+	if i, ok := tree.IndexOf(5); i != -1 || ok {
+		t.Errorf("IndexOf on empty tree should be -1/false: %d/%v\n", i, ok)
+	}
+
+	// even numbers 0..998
+	for j := 0; j < 1000; j += 2 {
+		tree.Add(j)
+	}
+
+	for j := -1; j <= 1000; j++ {
+		r := tree.Rank(j)
+		if r != (j+1)/2 {
+			t.Errorf("Rank(%d) should be %d: %d\n", j, (j+1)/2, r)
+		}
+
+		i, ok := tree.IndexOf(j)
+		if j >= 0 && j < 1000 && j%2 == 0 {
+			if !ok || i != j/2 {
+				t.Errorf("IndexOf(%d) should be %d/true: %d/%v\n", j, j/2, i, ok)
+			} else if v := tree.At(i); v == nil || *v != j {
+				t.Errorf("IndexOf(%d) does not match At: %v\n", j, v)
+			}
+		} else if ok || i != -1 {
+			t.Errorf("IndexOf(%d) should be -1/false: %d/%v\n", j, i, ok)
+		}
+	}
+
+	// duplicates: IndexOf finds the first equal element
+
+	tree = NewOrdered[int](AllowDuplicates)
+	for _, v := range []int{1, 2, 2, 2, 3} {
+		tree.Add(v)
+	}
+
+	if i, ok := tree.IndexOf(2); i != 1 || !ok {
+		t.Errorf("IndexOf(2) with duplicates should be 1/true: %d/%v\n", i, ok)
+	}
+
+	if r := tree.Rank(3); r != 4 {
+		t.Errorf("Rank(3) with duplicates should be 4: %d\n", r)
+	}
+}
+
+func TestMapRank(t *testing.T) {
+	m := NewMapOrdered[string, int]()
+
+	m.Add("b", 2)
+	m.Add("d", 4)
+	m.Add("f", 6)
+
+	if r := m.Rank("e"); r != 2 {
+		t.Errorf("Rank(e) should be 2: %d\n", r)
+	}
+
+	if i, ok := m.IndexOf("f"); i != 2 || !ok {
+		t.Errorf("IndexOf(f) should be 2/true: %d/%v\n", i, ok)
+	}
+
+	if i, ok := m.IndexOf("a"); i != -1 || ok {
+		t.Errorf("IndexOf(a) should be -1/false: %d/%v\n", i, ok)
+	}
+}
+
+func TestEqualRange(t *testing.T) {
+	tree := NewOrdered[int](AllowDuplicates)
+
+	if start, end := tree.EqualRange(5); start != 0 || end != 0 {
+		t.Errorf("EqualRange on empty tree should be 0/0: %d/%d\n", start, end)
+	}
+
+	counts := make([]int, 50)
+	for j := 0; j < 2000; j++ {
+		v := rand.Intn(50)
+		if v == 25 {
+			continue
+		}
+		counts[v]++
+		tree.Add(v)
+	}
+
+	start := 0
+	for v, n := range counts {
+		s, e := tree.EqualRange(v)
+		if s != start || e != start+n {
+			t.Errorf("EqualRange(%d) should be %d/%d: %d/%d\n", v, start, start+n, s, e)
+		}
+		if c := tree.CountEqual(v); c != n {
+			t.Errorf("CountEqual(%d) should be %d: %d\n", v, n, c)
+		}
+		c := 0
+		for x := range tree.FindAll(v) {
+			if x != v {
+				t.Errorf("FindAll(%d) returned %d\n", v, x)
+			}
+			c++
+		}
+		if c != n {
+			t.Errorf("FindAll(%d) should yield %d elements: %d\n", v, n, c)
+		}
+		start += n
+	}
+
+	// test RemoveAll
+
+	total := tree.Len()
+	for _, v := range []int{0, 25, 49, 10} {
+		if n := tree.RemoveAll(v); n != counts[v] {
+			t.Errorf("RemoveAll(%d) should remove %d: %d\n", v, counts[v], n)
+		}
+		total -= counts[v]
+		if tree.Len() != total || tree.Contains(v) {
+			t.Errorf("RemoveAll(%d) should leave %d elements: %d\n", v, total, tree.Len())
+		}
+		checkTree(t, tree)
+	}
+}